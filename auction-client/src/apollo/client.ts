@@ -1,20 +1,43 @@
-import { ApolloClient, InMemoryCache, HttpLink, split } from '@apollo/client';
+import { ApolloClient, InMemoryCache, HttpLink, from, split } from '@apollo/client';
+import { RetryLink } from '@apollo/client/link/retry';
 import { GraphQLWsLink } from '@apollo/client/link/subscriptions';
 import { getMainDefinition } from '@apollo/client/utilities';
 import { createClient } from 'graphql-ws';
 
+// Bearer token for bidding, e.g. one issued by `go run ./cmd/auction-token -sub alice`
+export const authToken: string = import.meta.env.VITE_AUTH_TOKEN ?? '';
+
+// The user the token was issued to, read from its "sub" claim (the server verifies it)
+export const currentUserId = (() => {
+  try {
+    const payload = authToken.split('.')[1].replace(/-/g, '+').replace(/_/g, '/');
+    return JSON.parse(atob(payload)).sub as string;
+  } catch {
+    return '';
+  }
+})();
+
+const authHeaders = authToken ? { Authorization: `Bearer ${authToken}` } : undefined;
+
 // HTTP connection for queries and mutations
 const httpLink = new HttpLink({
   uri: 'http://localhost:8080/query',
+  headers: authHeaders,
+});
+
+// Retry queries and mutations that failed on the network. A retried placeBid
+// resends its idempotencyKey, so the server never records the bid twice.
+const retryLink = new RetryLink({
+  delay: { initial: 300, max: 3000, jitter: true },
+  attempts: { max: 3 },
 });
 
 // WebSocket connection for subscriptions
 const wsLink = new GraphQLWsLink(
   createClient({
     url: 'ws://localhost:8080/query',
-    connectionParams: {
-      // Add authentication headers here if needed
-    },
+    // Sent in the connection_init message
+    connectionParams: authHeaders,
   })
 );
 
@@ -28,7 +51,7 @@ const splitLink = split(
     );
   },
   wsLink,   // Use WebSocket for subscriptions
-  httpLink  // Use HTTP for queries and mutations
+  from([retryLink, httpLink])  // Use HTTP for queries and mutations
 );
 
 // Create Apollo Client
@@ -5,11 +5,17 @@ import {
   PLACE_BID,
   GET_CURRENT_AUCTION,
   AUCTION_EVENTS_SUBSCRIPTION,
+  GET_ME,
+  REGISTER_BIDDER,
 } from '../graphql/operations';
+import { currentUserId } from '../apollo/client';
 import { Timer, DollarSign, User, AlertCircle, Play, TrendingUp, Settings } from 'lucide-react';
 
+// Money values arrive as exact decimal strings with a currency code, e.g. "150.50 USD"
+const amountOf = (money: string) => money.split(' ')[0];
+
 const AuctionDashboard: React.FC = () => {
-  const [userId] = useState(`User${Math.floor(Math.random() * 1000)}`);
+  const userId = currentUserId || 'not signed in';
   const [bidAmount, setBidAmount] = useState('');
   const [bidError, setBidError] = useState('');
   const [bidSuccess, setBidSuccess] = useState(false);
@@ -26,6 +32,14 @@ const AuctionDashboard: React.FC = () => {
 
   // GraphQL operations
   const { data: queryData, refetch } = useQuery(GET_CURRENT_AUCTION);
+  const { data: meData, refetch: refetchMe } = useQuery(GET_ME, { skip: !currentUserId });
+
+  const [registerBidder, { loading: registering }] = useMutation(REGISTER_BIDDER, {
+    onCompleted: () => refetchMe(),
+    onError: (error) => {
+      setBidError(error.message);
+    },
+  });
   
   const [createAuction, { loading: creatingAuction }] = useMutation(CREATE_AUCTION, {
     onCompleted: (data) => {
@@ -47,16 +61,34 @@ const AuctionDashboard: React.FC = () => {
       setBidSuccess(true);
       setTimeout(() => setBidSuccess(false), 3000);
       refetch();
+      refetchMe();
     },
     onError: (error) => {
-      setBidError(error.message);
+      // The server's error code and context spare a refetch to explain a refusal
+      const extensions = error.graphQLErrors[0]?.extensions ?? {};
+      switch (extensions.code) {
+        case 'BID_TOO_LOW':
+          setBidError(`Bid too low: the current bid is ${amountOf(String(extensions.currentBid))}`);
+          break;
+        case 'INSUFFICIENT_CREDIT':
+          setBidError(`Not enough credit: ${extensions.available} available`);
+          break;
+        case 'RATE_LIMITED':
+          setBidError(`Too many bids: try again in ${extensions.retryAfter}s`);
+          break;
+        default:
+          setBidError(error.message);
+      }
     },
   });
 
   const { data: subData } = useSubscription(AUCTION_EVENTS_SUBSCRIPTION, {
     onData: ({ data }) => {
       const event = data.data?.auctionEvents;
-      if (event?.auction) {
+      // Follow the auction on screen, switching over when a new one starts
+      const isOtherAuction =
+        auctionData && event?.auction?.id !== auctionData.id && event?.type !== 'AUCTION_STARTED';
+      if (event?.auction && !isOtherAuction) {
         const newTimeRemaining = event.auction.timeRemaining;
         const oldTimeRemaining = previousTimeRef.current;
         
@@ -65,8 +97,8 @@ const AuctionDashboard: React.FC = () => {
         // Update auction data
         setAuctionData(event.auction);
         
-        // Check if time was extended (time increased instead of decreasing)
-        if (event.type === 'BID_PLACED' && newTimeRemaining > oldTimeRemaining) {
+        // A late bid pushed the end time back
+        if (event.type === 'AUCTION_EXTENDED') {
           console.log(`🔔 AUCTION EXTENDED! ${oldTimeRemaining}s → ${newTimeRemaining}s`);
           setAuctionExtended(true);
           setTimeout(() => setAuctionExtended(false), 3000);
@@ -85,14 +117,14 @@ const AuctionDashboard: React.FC = () => {
       setAuctionData(queryData.currentAuction);
       setLocalTimeRemaining(queryData.currentAuction.timeRemaining);
       previousTimeRef.current = queryData.currentAuction.timeRemaining;
-      setBidAmount(queryData.currentAuction.nextBid.toString());
+      setBidAmount(amountOf(queryData.currentAuction.nextBid));
     }
   }, [queryData]);
 
   // Update bid amount when auction changes
   useEffect(() => {
     if (auctionData?.nextBid) {
-      setBidAmount(auctionData.nextBid.toString());
+      setBidAmount(amountOf(auctionData.nextBid));
     }
   }, [auctionData?.nextBid]);
 
@@ -137,7 +169,7 @@ const AuctionDashboard: React.FC = () => {
     try {
       await createAuction({
         variables: {
-          startingBid: startBid,
+          startingBid: startingBid.trim(),
           duration: dur,
           extendedBidding: extendedBidding,
         },
@@ -160,8 +192,10 @@ const AuctionDashboard: React.FC = () => {
     try {
       await placeBid({
         variables: {
-          userId,
-          amount,
+          auctionId: auctionData.id,
+          amount: bidAmount.trim(),
+          // One key per click; retries of this bid reuse it
+          idempotencyKey: crypto.randomUUID(),
         },
       });
     } catch (err) {
@@ -312,6 +346,12 @@ const AuctionDashboard: React.FC = () => {
   // RENDER: Active or Ended Auction
   const isActive = auctionData.status === 'ACTIVE';
   const isEnding = localTimeRemaining <= 10;
+  const closedLabel =
+    auctionData.status === 'PAUSED'
+      ? 'Auction Paused'
+      : auctionData.status === 'CANCELLED'
+        ? 'Auction Cancelled'
+        : 'Auction Ended';
 
   return (
     <div className="min-h-screen bg-gradient-to-br from-gray-900 via-gray-800 to-gray-900 p-6">
@@ -322,6 +362,29 @@ const AuctionDashboard: React.FC = () => {
           <p className="text-gray-400">
             Your ID: <span className="text-blue-400 font-semibold">{userId}</span>
           </p>
+          {currentUserId && meData && !meData.me && (
+            <button
+              onClick={() => registerBidder({ variables: { displayName: currentUserId } })}
+              disabled={registering}
+              className="mt-2 bg-blue-600 hover:bg-blue-700 disabled:bg-blue-800 text-white px-4 py-1 rounded-lg text-sm font-semibold transition"
+            >
+              {registering ? 'Registering...' : 'Register to bid'}
+            </button>
+          )}
+          {meData?.me && !meData.me.canBid && (
+            <p className="mt-2 text-yellow-400 text-sm">
+              {meData.me.suspended
+                ? 'Your bidder account is suspended'
+                : 'Your bidder account is awaiting verification'}
+            </p>
+          )}
+          {meData?.me?.canBid && (
+            <p className="mt-2 text-gray-400 text-sm">
+              Credit: <span className="text-green-400 font-semibold">{meData.me.credit.join(', ') || 'none'}</span>
+              {meData.me.holds.length > 0 &&
+                ` (holding ${meData.me.holds.map((h: { amount: string }) => h.amount).join(', ')})`}
+            </p>
+          )}
         </div>
 
         {/* Main Auction Card */}
@@ -347,7 +410,7 @@ const AuctionDashboard: React.FC = () => {
                   ? isEnding
                     ? 'Ending Soon!'
                     : 'Auction Active'
-                  : 'Auction Ended'}
+                  : closedLabel}
                 {auctionData.extendedBidding && isActive && (
                   <span className="ml-2 text-xs bg-white/20 px-2 py-1 rounded">
                     Extended Bidding ON
@@ -373,7 +436,7 @@ const AuctionDashboard: React.FC = () => {
               <div className="text-gray-400 text-sm mb-2">CURRENT BID</div>
               <div className="text-6xl font-bold text-white flex items-center justify-center gap-2">
                 <DollarSign size={48} className="text-green-500" />
-                {auctionData.currentBid.toFixed(2)}
+                {amountOf(auctionData.currentBid)}
               </div>
 
               {auctionData.currentWinner && (
@@ -432,7 +495,7 @@ const AuctionDashboard: React.FC = () => {
                     <span className="text-gray-400">Next minimum bid:</span>
                     <span className="text-green-400 font-semibold flex items-center gap-1">
                       <TrendingUp size={14} />
-                      ${auctionData.nextBid.toFixed(2)}
+                      ${amountOf(auctionData.nextBid)}
                     </span>
                   </div>
 
@@ -463,9 +526,15 @@ const AuctionDashboard: React.FC = () => {
               <div className="text-center">
                 <div className="bg-gray-700/50 rounded-xl p-8 border border-gray-600">
                   <h3 className="text-2xl font-bold text-white mb-4">
-                    Auction Ended
+                    {closedLabel}
                   </h3>
-                  {auctionData.currentWinner ? (
+                  {auctionData.status === 'PAUSED' ? (
+                    <p className="text-gray-400">
+                      Bidding is on hold - {localTimeRemaining}s will remain when it resumes
+                    </p>
+                  ) : auctionData.status === 'CANCELLED' ? (
+                    <p className="text-gray-400">The auction was cancelled and all bids are void</p>
+                  ) : auctionData.currentWinner ? (
                     <div>
                       <p className="text-gray-300 mb-2">Winner:</p>
                       <div className="text-3xl font-bold text-green-500 flex items-center justify-center gap-2 mb-4">
@@ -475,10 +544,12 @@ const AuctionDashboard: React.FC = () => {
                       <p className="text-gray-400">
                         Winning bid:{' '}
                         <span className="text-white font-semibold">
-                          ${auctionData.currentBid.toFixed(2)}
+                          ${amountOf(auctionData.currentBid)}
                         </span>
                       </p>
                     </div>
+                  ) : auctionData.status === 'RESERVE_NOT_MET' ? (
+                    <p className="text-gray-400">Reserve price not met - no winner</p>
                   ) : (
                     <p className="text-gray-400">No bids were placed</p>
                   )}
@@ -3,7 +3,7 @@ import { gql } from '@apollo/client';
 // Mutation: Create a new auction
 export const CREATE_AUCTION = gql`
   mutation CreateAuction(
-    $startingBid: Float!
+    $startingBid: Money!
     $duration: Int
     $extendedBidding: Boolean
   ) {
@@ -18,6 +18,7 @@ export const CREATE_AUCTION = gql`
       currentWinner
       duration
       extendedBidding
+      reserveMet
       status
       nextBid
       timeRemaining
@@ -27,9 +28,10 @@ export const CREATE_AUCTION = gql`
 
 // Mutation: Place a bid
 export const PLACE_BID = gql`
-  mutation PlaceBid($userId: String!, $amount: Float!) {
-    placeBid(userId: $userId, amount: $amount) {
+  mutation PlaceBid($auctionId: ID!, $amount: Money!, $idempotencyKey: String) {
+    placeBid(auctionId: $auctionId, amount: $amount, idempotencyKey: $idempotencyKey) {
       id
+      auctionId
       userId
       amount
       timestamp
@@ -58,6 +60,7 @@ export const GET_CURRENT_AUCTION = gql`
 export const AUCTION_EVENTS_SUBSCRIPTION = gql`
   subscription AuctionEvents {
     auctionEvents {
+      sequence
       type
       auction {
         id
@@ -76,4 +79,35 @@ export const AUCTION_EVENTS_SUBSCRIPTION = gql`
       error
     }
   }
-`;
\ No newline at end of file
+`;
+
+// Query: The signed-in bidder's profile (null until registered)
+export const GET_ME = gql`
+  query Me {
+    me {
+      id
+      displayName
+      verification
+      suspended
+      canBid
+      credit
+      holds {
+        auctionId
+        amount
+      }
+    }
+  }
+`;
+
+// Mutation: Register the signed-in user as a bidder
+export const REGISTER_BIDDER = gql`
+  mutation RegisterBidder($displayName: String!) {
+    registerBidder(displayName: $displayName) {
+      id
+      displayName
+      verification
+      suspended
+      canBid
+    }
+  }
+`;
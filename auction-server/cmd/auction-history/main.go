@@ -0,0 +1,73 @@
+// Command auction-history rebuilds auctions from the append-only event log and
+// prints their full history, e.g. to settle a disputed result.
+//
+//	go run ./cmd/auction-history -log events.log              # summary of every auction
+//	go run ./cmd/auction-history -log events.log -auction auction-3
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+func main() {
+	logPath := flag.String("log", os.Getenv("EVENT_LOG_PATH"), "path to the event log (default $EVENT_LOG_PATH)")
+	auctionID := flag.String("auction", "", "auction to rebuild; lists all auctions when empty")
+	flag.Parse()
+
+	if *logPath == "" {
+		log.Fatal("no event log given: pass -log or set EVENT_LOG_PATH")
+	}
+
+	records, err := eventlog.ReadAll(*logPath)
+	if err != nil {
+		log.Fatalf("failed to read event log: %v", err)
+	}
+
+	auctions, err := eventlog.Replay(records)
+	if err != nil {
+		log.Fatalf("failed to replay event log: %v", err)
+	}
+
+	if *auctionID == "" {
+		for _, auction := range auctions {
+			printSummary(auction)
+		}
+		return
+	}
+
+	var auction *model.Auction
+	for _, a := range auctions {
+		if a.ID == *auctionID {
+			auction = a
+			break
+		}
+	}
+	if auction == nil {
+		log.Fatalf("auction %s not found in %s", *auctionID, *logPath)
+	}
+
+	fmt.Printf("History of %s:\n", auction.ID)
+	for _, rec := range eventlog.History(records, auction.ID) {
+		fmt.Printf("  %s\n", rec)
+	}
+	fmt.Println()
+	printSummary(auction)
+}
+
+// printSummary prints the replayed final state of an auction
+func printSummary(auction *model.Auction) {
+	winner := "-"
+	if auction.CurrentWinner != nil {
+		winner = *auction.CurrentWinner
+	}
+	fmt.Printf("%s status=%s currentBid=%s winner=%s bids=%d start=%s end=%s\n",
+		auction.ID, auction.Status, auction.CurrentBid, winner, len(auction.Bids),
+		auction.StartTime.Format(time.RFC3339), auction.EndTime.Format(time.RFC3339))
+}
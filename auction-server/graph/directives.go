@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// Directives implements the schema directives that guard fields by role
+func Directives() DirectiveRoot {
+	return DirectiveRoot{
+		HasRole:      hasRole,
+		RedactUnless: redactUnless,
+	}
+}
+
+// hasRole resolves the field only for users holding one of the roles
+func hasRole(ctx context.Context, obj any, next graphql.Resolver, roles []model.Role) (any, error) {
+	if _, ok := auth.ClaimsFromContext(ctx); !ok {
+		return nil, model.ErrUnauthenticated
+	}
+	if !holdsAnyRole(ctx, roles) {
+		return nil, model.ErrForbidden
+	}
+	return next(ctx)
+}
+
+// redactUnless masks the user ID the field resolves to unless the caller holds
+// one of the roles or is that user
+func redactUnless(ctx context.Context, obj any, next graphql.Resolver, roles []model.Role) (any, error) {
+	res, err := next(ctx)
+	if err != nil || holdsAnyRole(ctx, roles) {
+		return res, err
+	}
+
+	switch userID := res.(type) {
+	case string:
+		return auth.RedactUserID(ctx, userID), nil
+	case *string:
+		if userID == nil {
+			return userID, nil
+		}
+		redacted := auth.RedactUserID(ctx, *userID)
+		return &redacted, nil
+	default:
+		return res, nil
+	}
+}
+
+func holdsAnyRole(ctx context.Context, roles []model.Role) bool {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, role := range roles {
+		if claims.HasRole(string(role)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/service"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func as(subject string, roles ...string) context.Context {
+	return auth.WithClaims(context.Background(), &auth.Claims{Subject: subject, Roles: roles})
+}
+
+func resolved(v any) func(context.Context) (any, error) {
+	return func(context.Context) (any, error) { return v, nil }
+}
+
+func TestHasRole(t *testing.T) {
+	sellers := []model.Role{model.RoleSeller, model.RoleAdmin}
+	cases := map[string]struct {
+		ctx  context.Context
+		want error
+	}{
+		"anonymous":      {context.Background(), model.ErrUnauthenticated},
+		"no roles":       {as("vera"), model.ErrForbidden},
+		"bidder":         {as("alice", "BIDDER"), model.ErrForbidden},
+		"seller":         {as("sam", "SELLER"), nil},
+		"admin":          {as("ada", "ADMIN"), nil},
+		"lowercase role": {as("sam", "seller"), nil},
+	}
+	for name, tc := range cases {
+		res, err := hasRole(tc.ctx, nil, resolved("ok"), sellers)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+		if tc.want == nil && res != "ok" {
+			t.Errorf("%s: expected the field to resolve, got %v", name, res)
+		}
+	}
+
+	if _, err := hasRole(as("vera"), nil, resolved("ok"), []model.Role{model.RoleViewer}); err != nil {
+		t.Errorf("expected a token without roles to count as VIEWER, got %v", err)
+	}
+}
+
+func TestHasRole_SellerManagesOwnAuctions(t *testing.T) {
+	st := store.NewAuctionStore()
+	users := service.NewUserService(store.NewMemoryRepository())
+	srv := handler.New(NewExecutableSchema(Config{
+		Resolvers:  NewResolver(service.NewAuctionService(st), users, service.NewWebhookService(store.NewMemoryRepository()), st),
+		Directives: Directives(),
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(ErrorPresenter)
+
+	if resp := post(t, srv, as("sam", "SELLER"), "10.0.0.1", `mutation { createAuction(startingBid: "100") { id } }`); len(resp.Errors) != 0 {
+		t.Fatalf("auction creation failed: %+v", resp.Errors)
+	}
+	auction := st.GetAuction("auction-1")
+	if auction == nil || auction.SellerID != "sam" {
+		t.Fatalf("expected sam's auction, got %+v", auction)
+	}
+
+	pause := fmt.Sprintf(`mutation { pauseAuction(auctionId: %q) { id } }`, auction.ID)
+	resp := post(t, srv, as("sue", "SELLER"), "10.0.0.2", pause)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "NOT_AUCTION_SELLER" {
+		t.Errorf("expected NOT_AUCTION_SELLER for another seller, got %+v", resp.Errors)
+	}
+	if auction.Status != model.AuctionStatusActive {
+		t.Errorf("expected the auction to stay ACTIVE, got %s", auction.Status)
+	}
+
+	if resp := post(t, srv, as("sam", "SELLER"), "10.0.0.1", pause); len(resp.Errors) != 0 {
+		t.Errorf("expected sam to pause their own auction, got %+v", resp.Errors)
+	}
+	resume := fmt.Sprintf(`mutation { resumeAuction(auctionId: %q) { id } }`, auction.ID)
+	if resp := post(t, srv, as("ada", "ADMIN"), "10.0.0.3", resume); len(resp.Errors) != 0 {
+		t.Errorf("expected an admin to resume any auction, got %+v", resp.Errors)
+	}
+	cancel := fmt.Sprintf(`mutation { cancelAuction(auctionId: %q) { id } }`, auction.ID)
+	if resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.4", cancel); len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "FORBIDDEN" {
+		t.Errorf("expected FORBIDDEN for a bidder, got %+v", resp.Errors)
+	}
+}
+
+func TestRedactUnless(t *testing.T) {
+	admins := []model.Role{model.RoleAdmin}
+	cases := map[string]struct {
+		ctx  context.Context
+		want string
+	}{
+		"anonymous":   {context.Background(), "b***"},
+		"other user":  {as("alice", "BIDDER"), "b***"},
+		"same user":   {as("bob", "BIDDER"), "bob"},
+		"admin":       {as("ada", "ADMIN"), "bob"},
+		"seller only": {as("sam", "SELLER"), "b***"},
+	}
+	for name, tc := range cases {
+		res, err := redactUnless(tc.ctx, nil, resolved("bob"), admins)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", name, err)
+		}
+		if res != tc.want {
+			t.Errorf("%s: expected %q, got %q", name, tc.want, res)
+		}
+	}
+
+	winner := "bob"
+	res, _ := redactUnless(context.Background(), nil, resolved(&winner), admins)
+	if got, ok := res.(*string); !ok || *got != "b***" || winner != "bob" {
+		t.Errorf("expected a redacted copy of the winner, got %v (original %q)", res, winner)
+	}
+	var noWinner *string
+	if res, _ := redactUnless(context.Background(), nil, resolved(noWinner), admins); res.(*string) != nil {
+		t.Errorf("expected no winner to stay nil, got %v", res)
+	}
+}
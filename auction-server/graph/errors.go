@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// errorCodes gives every model error the machine-readable code clients see in
+// extensions.code. An error can wrap several, so they are checked in order,
+// most specific first: what went wrong with the bid before why the auction or
+// the caller couldn't take it.
+var errorCodes = []struct {
+	sentinel error
+	code     string
+}{
+	{model.ErrBidTooLow, "BID_TOO_LOW"},
+	{model.ErrAlreadyBid, "ALREADY_BID"},
+	{model.ErrIdempotencyKeyReused, "IDEMPOTENCY_KEY_REUSED"},
+	{model.ErrInvalidIdempotencyKey, "INVALID_IDEMPOTENCY_KEY"},
+	{model.ErrInsufficientCredit, "INSUFFICIENT_CREDIT"},
+	{model.ErrBuyNowUnavailable, "BUY_NOW_UNAVAILABLE"},
+	{model.ErrInvalidBidAmount, "INVALID_BID_AMOUNT"},
+	{model.ErrInvalidQuantity, "INVALID_QUANTITY"},
+	{model.ErrUnsupportedForType, "UNSUPPORTED_FOR_AUCTION_TYPE"},
+
+	{model.ErrUnknownBidder, "BIDDER_NOT_REGISTERED"},
+	{model.ErrBidderNotVerified, "BIDDER_NOT_VERIFIED"},
+	{model.ErrBidderSuspended, "BIDDER_SUSPENDED"},
+	{model.ErrAlreadyRegistered, "ALREADY_REGISTERED"},
+	{model.ErrInvalidDisplayName, "INVALID_DISPLAY_NAME"},
+
+	{model.ErrBidTooLate, "AUCTION_ENDED"},
+	{model.ErrAuctionPaused, "AUCTION_PAUSED"},
+	{model.ErrAuctionNotStarted, "AUCTION_NOT_STARTED"},
+	{model.ErrInvalidStatusChange, "INVALID_STATUS_CHANGE"},
+	{model.ErrNoActiveAuction, "AUCTION_NOT_ACTIVE"},
+	{model.ErrAuctionNotFound, "AUCTION_NOT_FOUND"},
+
+	{model.ErrInvalidDuration, "INVALID_DURATION"},
+	{model.ErrInvalidStartingBid, "INVALID_STARTING_BID"},
+	{model.ErrInvalidReservePrice, "INVALID_RESERVE_PRICE"},
+	{model.ErrInvalidAuctionType, "INVALID_AUCTION_TYPE"},
+	{model.ErrInvalidPriceDrop, "INVALID_PRICE_DROP"},
+	{model.ErrInvalidPricingRule, "INVALID_PRICING_RULE"},
+	{model.ErrInvalidBuyNowPrice, "INVALID_BUY_NOW_PRICE"},
+	{model.ErrInvalidIncrements, "INVALID_INCREMENTS"},
+	{model.ErrInvalidExtensionPolicy, "INVALID_EXTENSION_POLICY"},
+
+	{model.ErrInvalidWebhookURL, "INVALID_WEBHOOK_URL"},
+	{model.ErrInvalidWebhookEvents, "INVALID_WEBHOOK_EVENTS"},
+	{model.ErrInvalidWebhookSecret, "INVALID_WEBHOOK_SECRET"},
+	{model.ErrWebhookNotFound, "WEBHOOK_NOT_FOUND"},
+	{model.ErrDeliveryNotFound, "DELIVERY_NOT_FOUND"},
+
+	{model.ErrCurrencyMismatch, "CURRENCY_MISMATCH"},
+	{model.ErrInvalidCurrency, "INVALID_CURRENCY"},
+	{model.ErrInvalidMoney, "INVALID_MONEY"},
+
+	{model.ErrNotAuctionSeller, "NOT_AUCTION_SELLER"},
+	{model.ErrForbidden, "FORBIDDEN"},
+	{model.ErrUnauthenticated, "UNAUTHENTICATED"},
+}
+
+// ErrorPresenter presents resolver errors as gqlgen does, adding the code of
+// the model error behind them to extensions, along with the context of a bid,
+// bidder or credit error, e.g. the current bid a BID_TOO_LOW bid has to beat
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+
+	extensions := errorExtensions(err)
+	if len(extensions) == 0 {
+		return presented
+	}
+	if presented.Extensions == nil {
+		presented.Extensions = make(map[string]any, len(extensions))
+	}
+	for key, value := range extensions {
+		presented.Extensions[key] = value
+	}
+	return presented
+}
+
+// errorExtensions returns the code and context of the model error err wraps,
+// or nil if it wraps none
+func errorExtensions(err error) map[string]any {
+	extensions := make(map[string]any)
+	for _, c := range errorCodes {
+		if errors.Is(err, c.sentinel) {
+			extensions["code"] = c.code
+			break
+		}
+	}
+
+	var bidErr *model.BidError
+	if errors.As(err, &bidErr) {
+		if bidErr.CurrentBid.IsPositive() {
+			extensions["currentBid"] = bidErr.CurrentBid.String()
+		}
+		if bidErr.AttemptedBid.IsPositive() {
+			extensions["attemptedBid"] = bidErr.AttemptedBid.String()
+		}
+		if bidErr.TimeRemaining > 0 {
+			extensions["timeRemaining"] = bidErr.TimeRemaining
+		}
+	}
+	var creditErr *model.CreditError
+	if errors.As(err, &creditErr) {
+		extensions["required"] = creditErr.Required.String()
+		extensions["available"] = creditErr.Available.String()
+	}
+	var bidderErr *model.BidderError
+	if errors.As(err, &bidderErr) && bidderErr.Reason != "" {
+		extensions["reason"] = bidderErr.Reason
+	}
+
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+// describedError gives a model error a user-friendly message while keeping it
+// reachable through errors.Is and errors.As, so the presenter still finds it
+type describedError struct {
+	message string
+	err     error
+}
+
+func (e *describedError) Error() string {
+	return e.message
+}
+
+func (e *describedError) Unwrap() error {
+	return e.err
+}
+
+// describe replaces err's message with a user-friendly one
+func describe(err error, format string, args ...any) error {
+	return &describedError{message: fmt.Sprintf(format, args...), err: err}
+}
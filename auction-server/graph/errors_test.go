@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/service"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestErrorPresenter_Codes(t *testing.T) {
+	usd := func(units int64) model.Money { return model.WholeUnits(units, "USD") }
+	cases := map[string]struct {
+		err  error
+		want map[string]any
+	}{
+		"bid too low": {
+			&model.BidError{Err: model.ErrBidTooLow, CurrentBid: usd(150), AttemptedBid: usd(120), TimeRemaining: 12},
+			map[string]any{"code": "BID_TOO_LOW", "currentBid": "150.00 USD", "attemptedBid": "120.00 USD", "timeRemaining": 12},
+		},
+		"described": {
+			describe(model.NewBidTooLateError(), "bid too late: auction has ended"),
+			map[string]any{"code": "AUCTION_ENDED"},
+		},
+		"wrapped": {
+			fmt.Errorf("failed to create auction: %w", model.ErrInvalidDuration),
+			map[string]any{"code": "INVALID_DURATION"},
+		},
+		"credit": {
+			&model.CreditError{UserID: "alice", Required: usd(200), Available: usd(50)},
+			map[string]any{"code": "INSUFFICIENT_CREDIT", "required": "200.00 USD", "available": "50.00 USD"},
+		},
+		"suspended": {
+			model.NewBidderSuspendedError("alice", "chargeback"),
+			map[string]any{"code": "BIDDER_SUSPENDED", "reason": "chargeback"},
+		},
+		"sentinel": {model.ErrForbidden, map[string]any{"code": "FORBIDDEN"}},
+		"two sentinels": {
+			&model.BidError{Err: errors.Join(model.ErrAuctionPaused, model.ErrBidTooLow), CurrentBid: usd(150)},
+			map[string]any{"code": "BID_TOO_LOW", "currentBid": "150.00 USD"},
+		},
+	}
+	for name, tc := range cases {
+		got := ErrorPresenter(context.Background(), tc.err)
+		if len(got.Extensions) != len(tc.want) {
+			t.Errorf("%s: expected extensions %v, got %v", name, tc.want, got.Extensions)
+			continue
+		}
+		for key, value := range tc.want {
+			if got.Extensions[key] != value {
+				t.Errorf("%s: expected %s %v, got %v", name, key, value, got.Extensions[key])
+			}
+		}
+	}
+
+	if got := ErrorPresenter(context.Background(), fmt.Errorf("boom")); got.Message != "boom" || got.Extensions != nil {
+		t.Errorf("expected an unknown error to pass through unchanged, got %+v", got)
+	}
+}
+
+func TestErrorPresenter_PlaceBidTooLow(t *testing.T) {
+	st := store.NewAuctionStore()
+	users := service.NewUserService(store.NewMemoryRepository(), service.WithAutoVerify())
+	svc := service.NewAuctionService(st, service.WithBidderRegistry(users))
+	srv := handler.New(NewExecutableSchema(Config{
+		Resolvers:  NewResolver(svc, users, service.NewWebhookService(store.NewMemoryRepository()), st),
+		Directives: Directives(),
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(ErrorPresenter)
+
+	if _, err := users.RegisterBidder(context.Background(), "alice", "Alice"); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	auction, err := svc.CreateAuction(context.Background(), service.CreateAuctionParams{StartingBid: model.WholeUnits(100, "USD"), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.1", fmt.Sprintf(`mutation { placeBid(auctionId: %q, amount: "90") { id } }`, auction.ID))
+	if len(resp.Errors) != 1 {
+		t.Fatalf("expected one error, got %+v", resp.Errors)
+	}
+	got := resp.Errors[0]
+	if got.Message != "bid too low: must be higher than current bid" {
+		t.Errorf("expected the friendly message to be kept, got %q", got.Message)
+	}
+	if got.Extensions["code"] != "BID_TOO_LOW" || got.Extensions["currentBid"] != "100.00 USD" || got.Extensions["attemptedBid"] != "90.00 USD" {
+		t.Errorf("expected BID_TOO_LOW with the current and attempted bid, got %v", got.Extensions)
+	}
+	if remaining, ok := got.Extensions["timeRemaining"].(float64); !ok || remaining <= 0 {
+		t.Errorf("expected the time remaining, got %v", got.Extensions["timeRemaining"])
+	}
+
+	resp = post(t, srv, as("bob", "BIDDER"), "10.0.0.2", fmt.Sprintf(`mutation { placeBid(auctionId: %q, amount: "150") { id } }`, auction.ID))
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "BIDDER_NOT_REGISTERED" {
+		t.Errorf("expected BIDDER_NOT_REGISTERED for bob, got %+v", resp.Errors)
+	}
+}
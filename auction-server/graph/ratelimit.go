@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/ratelimit"
+)
+
+// AnyMutation names the rate limit for mutations without a limit of their own
+const AnyMutation = "*"
+
+// DefaultRateLimits returns the limits applied unless configured otherwise
+func DefaultRateLimits() map[string]ratelimit.Rule {
+	return map[string]ratelimit.Rule{
+		"placeBid":    {Rate: 5, Burst: 10},
+		"placeMaxBid": {Rate: 2, Burst: 5},
+		"buyNow":      {Rate: 1, Burst: 3},
+		AnyMutation:   {Rate: 10, Burst: 20},
+	}
+}
+
+// RateLimit is a gqlgen extension that refuses mutations once the caller has
+// used up their token bucket. Each mutation has its own buckets, one per
+// authenticated user and one per client IP, and a call must get a token
+// from both.
+type RateLimit struct {
+	limiters map[string]*ratelimit.Limiter
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = RateLimit{}
+
+// NewRateLimit creates the extension from per-mutation rules; the AnyMutation
+// rule covers the rest, and without one they aren't limited
+func NewRateLimit(rules map[string]ratelimit.Rule, c clock.Clock) RateLimit {
+	limiters := make(map[string]*ratelimit.Limiter, len(rules))
+	for name, rule := range rules {
+		limiters[name] = ratelimit.New(rule, c)
+	}
+	return RateLimit{limiters: limiters}
+}
+
+// ExtensionName implements graphql.HandlerExtension
+func (RateLimit) ExtensionName() string {
+	return "RateLimit"
+}
+
+// Validate implements graphql.HandlerExtension
+func (RateLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation refuses the whole operation before it runs if any of its
+// mutations is over its limit. A refused operation gets back every token its
+// mutations took, since none of them ran.
+func (r RateLimit) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Mutation {
+		return next(ctx)
+	}
+
+	var taken []token
+	for _, field := range graphql.CollectFields(oc, oc.Operation.SelectionSet, []string{"Mutation"}) {
+		tokens, retryAfter, limited := r.take(ctx, field.Name)
+		taken = append(taken, tokens...)
+		if limited {
+			for _, t := range taken {
+				t.limiter.Refund(t.key)
+			}
+			return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{rateLimitedError(field.Name, retryAfter)}})
+		}
+	}
+	return next(ctx)
+}
+
+// token is one taken from a limiter's bucket, so it can be refunded
+type token struct {
+	limiter *ratelimit.Limiter
+	key     string
+}
+
+// take takes a token for the mutation from each of the caller's buckets. It
+// returns the tokens it got and reports whether any bucket was empty.
+func (r RateLimit) take(ctx context.Context, mutation string) ([]token, time.Duration, bool) {
+	limiter, ok := r.limiters[mutation]
+	if !ok {
+		if limiter, ok = r.limiters[AnyMutation]; !ok {
+			return nil, 0, false
+		}
+	}
+
+	// Keys name the mutation, so mutations sharing the fallback rule don't
+	// share buckets
+	var keys []string
+	if userID, ok := auth.SubjectFromContext(ctx); ok {
+		keys = append(keys, mutation+"|user:"+userID)
+	}
+	if ip, ok := ratelimit.ClientIPFromContext(ctx); ok {
+		keys = append(keys, mutation+"|ip:"+ip)
+	}
+
+	var tokens []token
+	var wait time.Duration
+	for _, key := range keys {
+		if ok, retryAfter := limiter.Allow(key); ok {
+			tokens = append(tokens, token{limiter, key})
+		} else {
+			wait = max(wait, retryAfter)
+		}
+	}
+	return tokens, wait, wait > 0
+}
+
+// rateLimitedError tells the caller to back off, with the whole number of
+// seconds to wait in extensions.retryAfter
+func rateLimitedError(mutation string, retryAfter time.Duration) *gqlerror.Error {
+	seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("rate limited: retry %s in %ds", mutation, seconds),
+		Path:    ast.Path{ast.PathName(mutation)},
+		Extensions: map[string]any{
+			"code":       "RATE_LIMITED",
+			"retryAfter": seconds,
+		},
+	}
+}
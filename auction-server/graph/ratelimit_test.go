@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/ratelimit"
+	"github.com/micahli/fl-auction/auction-server/internal/service"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+type graphQLResponse struct {
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// post sends a GraphQL request to h as the given user from the given IP
+func post(t *testing.T, h http.Handler, ctx context.Context, ip string, query string) graphQLResponse {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body))).WithContext(ratelimit.WithClientIP(ctx, ip))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp graphQLResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func TestRateLimit_RefusesMutationsOverLimit(t *testing.T) {
+	st := store.NewAuctionStore()
+	users := service.NewUserService(store.NewMemoryRepository())
+	srv := handler.New(NewExecutableSchema(Config{
+		Resolvers:  NewResolver(service.NewAuctionService(st), users, service.NewWebhookService(store.NewMemoryRepository()), st),
+		Directives: Directives(),
+	}))
+	srv.AddTransport(transport.POST{})
+	clk := clock.NewFake(time.Now())
+	srv.Use(NewRateLimit(map[string]ratelimit.Rule{"registerBidder": {Rate: 1.0 / 60, Burst: 1}}, clk))
+
+	register := `mutation { registerBidder(displayName: "x") { id } }`
+	if resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.1", register); len(resp.Errors) != 0 {
+		t.Fatalf("expected the first registration to go through, got %+v", resp.Errors)
+	}
+
+	resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.2", register)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "RATE_LIMITED" || resp.Errors[0].Extensions["retryAfter"] != float64(60) {
+		t.Fatalf("expected RATE_LIMITED for alice from another IP with a 60s retry-after, got %+v", resp.Errors)
+	}
+	if resp := post(t, srv, as("bob", "BIDDER"), "10.0.0.1", register); len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "RATE_LIMITED" {
+		t.Fatalf("expected RATE_LIMITED for bob from alice's IP, got %+v", resp.Errors)
+	}
+	if resp := post(t, srv, as("carol", "BIDDER"), "10.0.0.3", register); len(resp.Errors) != 0 {
+		t.Errorf("expected carol from her own IP to go through, got %+v", resp.Errors)
+	}
+	if resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.1", `{ auctions { id } }`); len(resp.Errors) != 0 {
+		t.Errorf("expected queries not to be limited, got %+v", resp.Errors)
+	}
+
+	clk.Advance(time.Minute)
+	if resp := post(t, srv, as("dave", "BIDDER"), "10.0.0.1", register); len(resp.Errors) != 0 {
+		t.Errorf("expected the IP's bucket to refill after a minute, got %+v", resp.Errors)
+	}
+}
+
+func TestRateLimit_RefusedOperationKeepsItsTokens(t *testing.T) {
+	st := store.NewAuctionStore()
+	users := service.NewUserService(store.NewMemoryRepository())
+	srv := handler.New(NewExecutableSchema(Config{
+		Resolvers:  NewResolver(service.NewAuctionService(st), users, service.NewWebhookService(store.NewMemoryRepository()), st),
+		Directives: Directives(),
+	}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(NewRateLimit(map[string]ratelimit.Rule{"registerBidder": {Rate: 1.0 / 60, Burst: 1}}, clock.NewFake(time.Now())))
+
+	// The first registration gets the only token, the second is refused and
+	// nothing runs, so the first must not have used it up
+	twice := `mutation { a: registerBidder(displayName: "x") { id } b: registerBidder(displayName: "y") { id } }`
+	if resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.1", twice); len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "RATE_LIMITED" {
+		t.Fatalf("expected RATE_LIMITED for two registrations at once, got %+v", resp.Errors)
+	}
+	if users.GetUser("alice") != nil {
+		t.Fatal("expected the refused operation not to register alice")
+	}
+	if resp := post(t, srv, as("alice", "BIDDER"), "10.0.0.1", `mutation { registerBidder(displayName: "x") { id } }`); len(resp.Errors) != 0 {
+		t.Errorf("expected a single registration to go through with the refunded token, got %+v", resp.Errors)
+	}
+}
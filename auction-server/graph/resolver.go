@@ -2,6 +2,12 @@
 package graph
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
 	"github.com/micahli/fl-auction/auction-server/internal/service"
 	"github.com/micahli/fl-auction/auction-server/internal/store"
 )
@@ -11,14 +17,90 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	service *service.AuctionService
-	store   *store.AuctionStore
+	service  *service.AuctionService
+	users    *service.UserService
+	webhooks *service.WebhookService
+	store    *store.AuctionStore
 }
 
 // NewResolver creates a new root resolver
-func NewResolver(svc *service.AuctionService, st *store.AuctionStore) *Resolver {
+func NewResolver(svc *service.AuctionService, users *service.UserService, webhooks *service.WebhookService, st *store.AuctionStore) *Resolver {
 	return &Resolver{
-		service: svc,
-		store:   st,
+		service:  svc,
+		users:    users,
+		webhooks: webhooks,
+		store:    st,
+	}
+}
+
+// adminActionError turns an operator action's error into a user-friendly message
+func adminActionError(action string, auctionID string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, model.ErrAuctionNotFound):
+		return describe(err, "auction %s not found", auctionID)
+	case errors.Is(err, model.ErrInvalidStatusChange):
+		return describe(err, "cannot %s auction %s in its current status", action, auctionID)
+	case errors.Is(err, model.ErrNotAuctionSeller):
+		return describe(err, "auction %s belongs to another seller", auctionID)
+	default:
+		return fmt.Errorf("failed to %s auction: %w", action, err)
+	}
+}
+
+// userActionError turns a bidder registry action's error into a user-friendly message
+func userActionError(action string, userID string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, model.ErrUnknownBidder):
+		return describe(err, "user %s is not registered", userID)
+	default:
+		return fmt.Errorf("failed to %s bidder: %w", action, err)
+	}
+}
+
+// bidderError explains why the caller may not bid, or returns nil if err has
+// nothing to do with their eligibility or credit
+func bidderError(err error) error {
+	var creditErr *model.CreditError
+	if errors.As(err, &creditErr) {
+		return describe(err, "insufficient credit: %s required, %s available", creditErr.Required, creditErr.Available)
+	}
+	var bidderErr *model.BidderError
+	if !errors.As(err, &bidderErr) {
+		return nil
+	}
+	switch {
+	case errors.Is(bidderErr, model.ErrUnknownBidder):
+		return describe(err, "register as a bidder before bidding")
+	case errors.Is(bidderErr, model.ErrBidderNotVerified):
+		return describe(err, "your bidder account has not been verified yet")
+	case errors.Is(bidderErr, model.ErrBidderSuspended):
+		if bidderErr.Reason != "" {
+			return describe(err, "your bidder account is suspended: %s", bidderErr.Reason)
+		}
+		return describe(err, "your bidder account is suspended")
+	default:
+		return bidderErr
+	}
+}
+
+// managingSeller returns the seller whose auctions the caller may manage, or
+// "" if they are an admin and may manage any
+func managingSeller(ctx context.Context) (string, error) {
+	if holdsAnyRole(ctx, []model.Role{model.RoleAdmin}) {
+		return "", nil
+	}
+	return currentUser(ctx)
+}
+
+// currentUser returns the user the request's bearer token was issued to
+func currentUser(ctx context.Context) (string, error) {
+	userID, ok := auth.SubjectFromContext(ctx)
+	if !ok {
+		return "", model.ErrUnauthenticated
 	}
+	return userID, nil
 }
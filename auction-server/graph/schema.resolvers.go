@@ -6,12 +6,25 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/service"
+	"github.com/micahli/fl-auction/auction-server/internal/sse"
 )
 
+// Increments returns the auction's increment schedule, one price band per entry
+func (r *auctionResolver) Increments(ctx context.Context, obj *model.Auction) ([]*model.IncrementTier, error) {
+	tiers := make([]*model.IncrementTier, len(obj.Increments))
+	for i := range obj.Increments {
+		tier := obj.Increments[i]
+		tiers[i] = &tier
+	}
+	return tiers, nil
+}
+
 // StartTime formats the auction start time for GraphQL
 func (r *auctionResolver) StartTime(ctx context.Context, obj *model.Auction) (string, error) {
 	return obj.StartTime.Format(time.RFC3339), nil
@@ -22,13 +35,46 @@ func (r *auctionResolver) EndTime(ctx context.Context, obj *model.Auction) (stri
 	return obj.EndTime.Format(time.RFC3339), nil
 }
 
+// OriginalEndTime formats the auction's end time before any extension for GraphQL
+func (r *auctionResolver) OriginalEndTime(ctx context.Context, obj *model.Auction) (string, error) {
+	return obj.OriginalEnd().Format(time.RFC3339), nil
+}
+
+// TimeRemaining returns the seconds left in the auction by the service's clock
+func (r *auctionResolver) TimeRemaining(ctx context.Context, obj *model.Auction) (int, error) {
+	return r.service.GetTimeRemaining(obj.ID), nil
+}
+
+// Pricing returns the pricing rule of a multi-unit lot, or null for a single item
+func (r *auctionResolver) Pricing(ctx context.Context, obj *model.Auction) (*model.PricingRule, error) {
+	if obj.Pricing == "" {
+		return nil, nil
+	}
+	return &obj.Pricing, nil
+}
+
+// BuyNowAvailable reports whether the auction can still be bought outright
+func (r *auctionResolver) BuyNowAvailable(ctx context.Context, obj *model.Auction) (bool, error) {
+	return r.service.BuyNowAvailable(obj), nil
+}
+
 // Timestamp formats the bid timestamp for GraphQL
 func (r *bidResolver) Timestamp(ctx context.Context, obj *model.Bid) (string, error) {
 	return obj.Timestamp.Format(time.RFC3339), nil
 }
 
+// NextDropAt formats the time of the next Dutch price drop for GraphQL
+func (r *dutchScheduleResolver) NextDropAt(ctx context.Context, obj *model.DutchSchedule) (string, error) {
+	return obj.NextDropAt.Format(time.RFC3339), nil
+}
+
+// PlacedAt formats when the credit was held for GraphQL
+func (r *holdResolver) PlacedAt(ctx context.Context, obj *model.Hold) (string, error) {
+	return obj.PlacedAt.Format(time.RFC3339), nil
+}
+
 // CreateAuction creates a new auction with the specified parameters
-func (r *mutationResolver) CreateAuction(ctx context.Context, startingBid float64, duration *int, extendedBidding *bool) (*model.Auction, error) {
+func (r *mutationResolver) CreateAuction(ctx context.Context, startingBid model.Money, duration *int, extendedBidding *bool, reservePrice *model.Money, typeArg *model.AuctionType, priceDropAmount *model.Money, priceDropInterval *int, floorPrice *model.Money, replaceableBids *bool, quantity *int, pricing *model.PricingRule, buyNowPrice *model.Money, startTime *string, currency *string, increments []*model.IncrementTier, extensionPolicy *model.ExtensionPolicy) (*model.Auction, error) {
 	// Set default values for optional parameters
 	d := 30
 	if duration != nil {
@@ -40,8 +86,53 @@ func (r *mutationResolver) CreateAuction(ctx context.Context, startingBid float6
 		eb = *extendedBidding
 	}
 
+	params := service.CreateAuctionParams{
+		StartingBid:       startingBid,
+		Duration:          d,
+		ExtendedBidding:   eb,
+		ReservePrice:      reservePrice,
+		PriceDropAmount:   priceDropAmount,
+		PriceDropInterval: priceDropInterval,
+		FloorPrice:        floorPrice,
+		BuyNowPrice:       buyNowPrice,
+	}
+	if replaceableBids != nil {
+		params.ReplaceableBids = *replaceableBids
+	}
+	if startTime != nil {
+		t, err := time.Parse(time.RFC3339, *startTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q: expected RFC 3339, e.g. 2024-01-02T15:04:05Z", *startTime)
+		}
+		params.StartTime = &t
+	}
+	if quantity != nil {
+		params.Quantity = *quantity
+	}
+	if pricing != nil {
+		params.Pricing = *pricing
+	}
+	if typeArg != nil {
+		params.Type = *typeArg
+	}
+	if currency != nil {
+		params.Currency = *currency
+	}
+	params.ExtensionPolicy = extensionPolicy
+	sellerID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params.SellerID = sellerID
+	if increments != nil {
+		params.Increments = make(model.IncrementSchedule, len(increments))
+		for i, tier := range increments {
+			params.Increments[i] = *tier
+		}
+	}
+
 	// Call the service to create the auction (access through Resolver)
-	auction, err := r.Resolver.service.CreateAuction(ctx, startingBid, d, eb)
+	auction, err := r.Resolver.service.CreateAuction(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create auction: %w", err)
 	}
@@ -49,19 +140,52 @@ func (r *mutationResolver) CreateAuction(ctx context.Context, startingBid float6
 	return auction, nil
 }
 
-// PlaceBid places a bid on the current auction
-func (r *mutationResolver) PlaceBid(ctx context.Context, userID string, amount float64) (*model.Bid, error) {
+// PlaceBid places a bid on the given auction
+func (r *mutationResolver) PlaceBid(ctx context.Context, auctionID string, amount model.Money, quantity *int, idempotencyKey *string) (*model.Bid, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := 1
+	if quantity != nil {
+		q = *quantity
+	}
+	key := ""
+	if idempotencyKey != nil {
+		key = *idempotencyKey
+	}
+
 	// Call the service to place the bid
-	bid, err := r.service.PlaceBid(ctx, userID, amount)
+	bid, err := r.service.PlaceBidIdempotent(ctx, key, auctionID, userID, amount, q)
 	if err != nil {
+		if bidderErr := bidderError(err); bidderErr != nil {
+			return nil, bidderErr
+		}
 		// Return user-friendly error messages
-		switch err {
-		case model.ErrBidTooLow:
-			return nil, fmt.Errorf("bid too low: must be higher than current bid")
-		case model.ErrBidTooLate:
-			return nil, fmt.Errorf("bid too late: auction has ended")
-		case model.ErrNoActiveAuction:
-			return nil, fmt.Errorf("no active auction available")
+		switch {
+		case errors.Is(err, model.ErrBidTooLow):
+			return nil, describe(err, "bid too low: must be higher than current bid")
+		case errors.Is(err, model.ErrBidTooLate):
+			return nil, describe(err, "bid too late: auction has ended")
+		case errors.Is(err, model.ErrNoActiveAuction):
+			return nil, describe(err, "no active auction available")
+		case errors.Is(err, model.ErrAuctionNotFound):
+			return nil, describe(err, "auction %s not found", auctionID)
+		case errors.Is(err, model.ErrAlreadyBid):
+			return nil, describe(err, "you have already placed your sealed bid on this auction")
+		case errors.Is(err, model.ErrInvalidQuantity):
+			return nil, describe(err, "invalid quantity: must be between 1 and the number of units in the lot")
+		case errors.Is(err, model.ErrAuctionNotStarted):
+			return nil, describe(err, "auction %s has not started yet", auctionID)
+		case errors.Is(err, model.ErrAuctionPaused):
+			return nil, describe(err, "auction %s is paused", auctionID)
+		case errors.Is(err, model.ErrCurrencyMismatch):
+			return nil, describe(err, "bid must be in the auction's currency")
+		case errors.Is(err, model.ErrInvalidIdempotencyKey):
+			return nil, describe(err, "invalid idempotency key: must be 1 to 255 bytes")
+		case errors.Is(err, model.ErrIdempotencyKeyReused):
+			return nil, describe(err, "idempotency key was already used for a different bid")
 		default:
 			return nil, fmt.Errorf("failed to place bid: %w", err)
 		}
@@ -70,9 +194,232 @@ func (r *mutationResolver) PlaceBid(ctx context.Context, userID string, amount f
 	return bid, nil
 }
 
-// CurrentAuction returns the current active or most recent auction
-func (r *queryResolver) CurrentAuction(ctx context.Context) (*model.Auction, error) {
-	auction := r.service.GetCurrentAuction()
+// PlaceMaxBid sets a hidden maximum that the server bids up to on the user's behalf
+func (r *mutationResolver) PlaceMaxBid(ctx context.Context, auctionID string, maxAmount model.Money) (*model.Bid, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bid, err := r.service.PlaceMaxBid(ctx, auctionID, userID, maxAmount)
+	if err != nil {
+		if bidderErr := bidderError(err); bidderErr != nil {
+			return nil, bidderErr
+		}
+		switch {
+		case errors.Is(err, model.ErrBidTooLow):
+			return nil, describe(err, "maximum too low: must cover the next bid and exceed your previous maximum")
+		case errors.Is(err, model.ErrBidTooLate):
+			return nil, describe(err, "bid too late: auction has ended")
+		case errors.Is(err, model.ErrNoActiveAuction):
+			return nil, describe(err, "no active auction available")
+		case errors.Is(err, model.ErrAuctionNotFound):
+			return nil, describe(err, "auction %s not found", auctionID)
+		case errors.Is(err, model.ErrUnsupportedForType):
+			return nil, describe(err, "maximum bids are not supported for this auction type")
+		case errors.Is(err, model.ErrAuctionNotStarted):
+			return nil, describe(err, "auction %s has not started yet", auctionID)
+		case errors.Is(err, model.ErrAuctionPaused):
+			return nil, describe(err, "auction %s is paused", auctionID)
+		case errors.Is(err, model.ErrCurrencyMismatch):
+			return nil, describe(err, "maximum must be in the auction's currency")
+		default:
+			return nil, fmt.Errorf("failed to place maximum bid: %w", err)
+		}
+	}
+
+	return bid, nil
+}
+
+// BuyNow ends the auction at once with the user as winner at the buy-now price
+func (r *mutationResolver) BuyNow(ctx context.Context, auctionID string) (*model.Auction, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	auction, err := r.service.BuyNow(ctx, auctionID, userID)
+	if err != nil {
+		if bidderErr := bidderError(err); bidderErr != nil {
+			return nil, bidderErr
+		}
+		switch {
+		case errors.Is(err, model.ErrBuyNowUnavailable):
+			return nil, describe(err, "buy-now is not available: bidding has passed the cutoff or no buy-now price was set")
+		case errors.Is(err, model.ErrBidTooLate):
+			return nil, describe(err, "too late: auction has ended")
+		case errors.Is(err, model.ErrNoActiveAuction):
+			return nil, describe(err, "no active auction available")
+		case errors.Is(err, model.ErrAuctionNotFound):
+			return nil, describe(err, "auction %s not found", auctionID)
+		case errors.Is(err, model.ErrAuctionNotStarted):
+			return nil, describe(err, "auction %s has not started yet", auctionID)
+		case errors.Is(err, model.ErrAuctionPaused):
+			return nil, describe(err, "auction %s is paused", auctionID)
+		default:
+			return nil, fmt.Errorf("failed to buy now: %w", err)
+		}
+	}
+
+	return auction, nil
+}
+
+// RegisterBidder creates the caller's bidder profile, pending verification
+func (r *mutationResolver) RegisterBidder(ctx context.Context, displayName string) (*model.User, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.users.RegisterBidder(ctx, userID, displayName)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrAlreadyRegistered):
+			return nil, describe(err, "you are already registered as a bidder")
+		case errors.Is(err, model.ErrInvalidDisplayName):
+			return nil, describe(err, "invalid display name: must not be blank or longer than 50 characters")
+		default:
+			return nil, fmt.Errorf("failed to register: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// VerifyBidder marks a bidder's identity as checked
+func (r *mutationResolver) VerifyBidder(ctx context.Context, userID string) (*model.User, error) {
+	user, err := r.users.VerifyBidder(ctx, userID)
+	return user, userActionError("verify", userID, err)
+}
+
+// RejectBidder marks a bidder's identity check as failed
+func (r *mutationResolver) RejectBidder(ctx context.Context, userID string) (*model.User, error) {
+	user, err := r.users.RejectBidder(ctx, userID)
+	return user, userActionError("reject", userID, err)
+}
+
+// SuspendBidder stops a bidder from bidding until reinstated
+func (r *mutationResolver) SuspendBidder(ctx context.Context, userID string, reason *string) (*model.User, error) {
+	why := ""
+	if reason != nil {
+		why = *reason
+	}
+	user, err := r.users.SuspendBidder(ctx, userID, why)
+	return user, userActionError("suspend", userID, err)
+}
+
+// ReinstateBidder lifts a bidder's suspension
+func (r *mutationResolver) ReinstateBidder(ctx context.Context, userID string) (*model.User, error) {
+	user, err := r.users.ReinstateBidder(ctx, userID)
+	return user, userActionError("reinstate", userID, err)
+}
+
+// DepositCredit adds to a bidder's credit
+func (r *mutationResolver) DepositCredit(ctx context.Context, userID string, amount model.Money) (*model.User, error) {
+	user, err := r.users.DepositCredit(ctx, userID, amount)
+	if errors.Is(err, model.ErrInvalidMoney) {
+		return nil, describe(err, "invalid deposit: must be a positive amount")
+	}
+	return user, userActionError("credit", userID, err)
+}
+
+// CancelAuction calls off an auction and voids its bids
+func (r *mutationResolver) CancelAuction(ctx context.Context, auctionID string) (*model.Auction, error) {
+	sellerID, err := managingSeller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auction, err := r.service.CancelAuction(ctx, auctionID, sellerID)
+	return auction, adminActionError("cancel", auctionID, err)
+}
+
+// PauseAuction freezes an active auction
+func (r *mutationResolver) PauseAuction(ctx context.Context, auctionID string) (*model.Auction, error) {
+	sellerID, err := managingSeller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auction, err := r.service.PauseAuction(ctx, auctionID, sellerID)
+	return auction, adminActionError("pause", auctionID, err)
+}
+
+// ResumeAuction reopens a paused auction
+func (r *mutationResolver) ResumeAuction(ctx context.Context, auctionID string) (*model.Auction, error) {
+	sellerID, err := managingSeller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auction, err := r.service.ResumeAuction(ctx, auctionID, sellerID)
+	return auction, adminActionError("resume", auctionID, err)
+}
+
+// ForceEndAuction closes an auction right away
+func (r *mutationResolver) ForceEndAuction(ctx context.Context, auctionID string) (*model.Auction, error) {
+	sellerID, err := managingSeller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auction, err := r.service.ForceEndAuction(ctx, auctionID, sellerID)
+	return auction, adminActionError("end", auctionID, err)
+}
+
+// RegisterWebhook registers a URL to receive auction events of the given types
+func (r *mutationResolver) RegisterWebhook(ctx context.Context, url string, eventTypes []model.AuctionEventType, secret string) (*model.Webhook, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := r.webhooks.RegisterWebhook(ctx, userID, url, eventTypes, secret)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidWebhookURL):
+			return nil, describe(err, "invalid webhook URL: must be an absolute http or https URL")
+		case errors.Is(err, model.ErrInvalidWebhookEvents):
+			return nil, describe(err, "invalid event types: must name at least one event type other than RESYNC_REQUIRED")
+		case errors.Is(err, model.ErrInvalidWebhookSecret):
+			return nil, describe(err, "invalid webhook secret: must be at least 16 bytes")
+		default:
+			return nil, fmt.Errorf("failed to register webhook: %w", err)
+		}
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook along with its queued deliveries
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	webhook, err := r.webhooks.DeleteWebhook(ctx, id)
+	switch {
+	case err == nil:
+		return webhook, nil
+	case errors.Is(err, model.ErrWebhookNotFound):
+		return nil, describe(err, "webhook %s not found", id)
+	default:
+		return nil, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+}
+
+// RetryWebhookDelivery puts a dead-letter delivery back in the queue
+func (r *mutationResolver) RetryWebhookDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	delivery, err := r.webhooks.RetryDelivery(ctx, id)
+	switch {
+	case err == nil:
+		return delivery, nil
+	case errors.Is(err, model.ErrDeliveryNotFound):
+		return nil, describe(err, "webhook delivery %s not found", id)
+	default:
+		return nil, fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+}
+
+// CurrentAuction returns the given auction, or the most recent auction when no ID is supplied
+func (r *queryResolver) CurrentAuction(ctx context.Context, auctionID *string) (*model.Auction, error) {
+	var auction *model.Auction
+	if auctionID != nil {
+		auction = r.service.GetAuction(*auctionID)
+	} else {
+		auction = r.service.GetCurrentAuction()
+	}
 	if auction == nil {
 		// Return nil without error - this is valid (no auction exists)
 		return nil, nil
@@ -81,33 +428,139 @@ func (r *queryResolver) CurrentAuction(ctx context.Context) (*model.Auction, err
 	return auction, nil
 }
 
-// AuctionEvents subscribes to real-time auction events
-func (r *subscriptionResolver) AuctionEvents(ctx context.Context) (<-chan *model.AuctionEvent, error) {
+// Auction returns a single auction by ID
+func (r *queryResolver) Auction(ctx context.Context, id string) (*model.Auction, error) {
+	return r.service.GetAuction(id), nil
+}
+
+// Auctions lists all auctions, optionally filtered by status
+func (r *queryResolver) Auctions(ctx context.Context, status *model.AuctionStatus) ([]*model.Auction, error) {
+	return r.service.ListAuctions(status), nil
+}
+
+// Me returns the caller's bidder profile, or nil if they haven't registered
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.users.GetUser(userID), nil
+}
+
+// Users lists every registered bidder
+func (r *queryResolver) Users(ctx context.Context) ([]*model.User, error) {
+	return r.users.ListUsers(), nil
+}
+
+// Webhooks lists every registered webhook
+func (r *queryResolver) Webhooks(ctx context.Context) ([]*model.Webhook, error) {
+	return r.webhooks.ListWebhooks(), nil
+}
+
+// WebhookDeliveries lists the queued deliveries with the given status
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, status model.DeliveryStatus) ([]*model.WebhookDelivery, error) {
+	return r.webhooks.ListDeliveries(status), nil
+}
+
+// SettledAt formats when the winner was charged for GraphQL
+func (r *settlementResolver) SettledAt(ctx context.Context, obj *model.Settlement) (string, error) {
+	return obj.SettledAt.Format(time.RFC3339), nil
+}
+
+// AuctionEvents subscribes to real-time events for one auction, or for all auctions when no ID is supplied.
+// With afterSequence, the events of that auction missed since the given sequence are replayed first.
+func (r *subscriptionResolver) AuctionEvents(ctx context.Context, auctionID *string, afterSequence *int) (<-chan *model.AuctionEvent, error) {
 	// Generate a unique subscriber ID
 	subscriberID := fmt.Sprintf("sub-%d", time.Now().UnixNano())
 
-	// Subscribe to auction events
-	eventChannel := r.service.Subscribe(subscriberID)
+	filter := ""
+	if auctionID != nil {
+		filter = *auctionID
+		if r.service.GetAuction(filter) == nil {
+			return nil, describe(model.ErrAuctionNotFound, "auction %s not found", filter)
+		}
+	}
 
-	// Clean up the subscription when the context is cancelled
+	// Sequence numbers are per auction, so resuming needs one. An SSE client
+	// may resume with a Last-Event-ID header instead of afterSequence.
+	if afterSequence == nil && filter != "" {
+		if lastEventID, ok := sse.LastEventIDFromContext(ctx); ok {
+			afterSequence = &lastEventID
+		}
+	}
+	if afterSequence != nil && filter == "" {
+		return nil, fmt.Errorf("afterSequence requires auctionId")
+	}
+
+	// Events queued ahead of the live stream
+	eventChannel, backlog := r.service.Follow(subscriberID, filter, afterSequence)
+
+	// Forward the backlog and then live events until the client goes away
+	out := make(chan *model.AuctionEvent, 10)
 	go func() {
-		<-ctx.Done()
-		r.service.Unsubscribe(subscriberID)
-	}()
+		defer close(out)
+		defer r.service.Unsubscribe(subscriberID)
+
+		for _, event := range backlog {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
 
-	// Optionally send the current auction state immediately upon subscription
-	if currentAuction := r.service.GetCurrentAuction(); currentAuction != nil {
-		// Send current state as first event (non-blocking)
-		go func() {
+		for {
 			select {
-			case eventChannel <- model.NewAuctionStartedEvent(currentAuction):
-			case <-time.After(1 * time.Second):
-				// Skip if channel is not ready
+			case event := <-eventChannel:
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
 			}
-		}()
+		}
+	}()
+
+	return out, nil
+}
+
+// SuspensionReason returns why the bidder was suspended; nil if they aren't or no reason was given
+func (r *userResolver) SuspensionReason(ctx context.Context, obj *model.User) (*string, error) {
+	if !obj.Suspended || obj.SuspensionReason == "" {
+		return nil, nil
+	}
+	return &obj.SuspensionReason, nil
+}
+
+// RegisteredAt formats the registration time for GraphQL
+func (r *userResolver) RegisteredAt(ctx context.Context, obj *model.User) (string, error) {
+	return obj.RegisteredAt.Format(time.RFC3339), nil
+}
+
+// VerifiedAt formats the verification time for GraphQL; nil until verified
+func (r *userResolver) VerifiedAt(ctx context.Context, obj *model.User) (*string, error) {
+	if obj.VerifiedAt == nil {
+		return nil, nil
 	}
+	verifiedAt := obj.VerifiedAt.Format(time.RFC3339)
+	return &verifiedAt, nil
+}
 
-	return eventChannel, nil
+// CreatedAt formats the registration time for GraphQL
+func (r *webhookResolver) CreatedAt(ctx context.Context, obj *model.Webhook) (string, error) {
+	return obj.CreatedAt.Format(time.RFC3339), nil
+}
+
+// NextAttemptAt formats when the delivery is next tried for GraphQL
+func (r *webhookDeliveryResolver) NextAttemptAt(ctx context.Context, obj *model.WebhookDelivery) (string, error) {
+	return obj.NextAttemptAt.Format(time.RFC3339), nil
+}
+
+// CreatedAt formats when the event was queued for GraphQL
+func (r *webhookDeliveryResolver) CreatedAt(ctx context.Context, obj *model.WebhookDelivery) (string, error) {
+	return obj.CreatedAt.Format(time.RFC3339), nil
 }
 
 // Auction returns AuctionResolver implementation.
@@ -116,17 +569,41 @@ func (r *Resolver) Auction() AuctionResolver { return &auctionResolver{r} }
 // Bid returns BidResolver implementation.
 func (r *Resolver) Bid() BidResolver { return &bidResolver{r} }
 
+// DutchSchedule returns DutchScheduleResolver implementation.
+func (r *Resolver) DutchSchedule() DutchScheduleResolver { return &dutchScheduleResolver{r} }
+
+// Hold returns HoldResolver implementation.
+func (r *Resolver) Hold() HoldResolver { return &holdResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Settlement returns SettlementResolver implementation.
+func (r *Resolver) Settlement() SettlementResolver { return &settlementResolver{r} }
+
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+// Webhook returns WebhookResolver implementation.
+func (r *Resolver) Webhook() WebhookResolver { return &webhookResolver{r} }
+
+// WebhookDelivery returns WebhookDeliveryResolver implementation.
+func (r *Resolver) WebhookDelivery() WebhookDeliveryResolver { return &webhookDeliveryResolver{r} }
+
 type auctionResolver struct{ *Resolver }
 type bidResolver struct{ *Resolver }
+type dutchScheduleResolver struct{ *Resolver }
+type holdResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type settlementResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
+type webhookResolver struct{ *Resolver }
+type webhookDeliveryResolver struct{ *Resolver }
@@ -0,0 +1,270 @@
+// Package auth verifies the JWT bearer tokens clients authenticate with and
+// carries the verified identity through request contexts.
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+)
+
+// Errors returned when a token is rejected
+var (
+	ErrMissingToken         = errors.New("missing bearer token")
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrTokenExpired         = errors.New("token has expired")
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrInvalidClaims        = errors.New("token claims rejected")
+)
+
+// Claims are the registered JWT claims the server looks at
+type Claims struct {
+	Subject   string           `json:"sub"`
+	Issuer    string           `json:"iss,omitempty"`
+	Audience  jwt.ClaimStrings `json:"aud,omitempty"`
+	ExpiresAt int64            `json:"exp,omitempty"` // Unix seconds
+	NotBefore int64            `json:"nbf,omitempty"`
+	IssuedAt  int64            `json:"iat,omitempty"`
+	Roles     []string         `json:"roles,omitempty"` // e.g. ["SELLER", "BIDDER"]
+}
+
+// HasRole reports whether the token grants role. A token without any roles
+// only grants "VIEWER".
+func (c *Claims) HasRole(role string) bool {
+	if len(c.Roles) == 0 {
+		return strings.EqualFold(role, "VIEWER")
+	}
+	for _, r := range c.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetExpirationTime implements jwt.Claims
+func (c *Claims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return numericDate(c.ExpiresAt), nil
+}
+
+// GetNotBefore implements jwt.Claims
+func (c *Claims) GetNotBefore() (*jwt.NumericDate, error) {
+	return numericDate(c.NotBefore), nil
+}
+
+// GetIssuedAt implements jwt.Claims
+func (c *Claims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return numericDate(c.IssuedAt), nil
+}
+
+// GetIssuer implements jwt.Claims
+func (c *Claims) GetIssuer() (string, error) {
+	return c.Issuer, nil
+}
+
+// GetSubject implements jwt.Claims
+func (c *Claims) GetSubject() (string, error) {
+	return c.Subject, nil
+}
+
+// GetAudience implements jwt.Claims
+func (c *Claims) GetAudience() (jwt.ClaimStrings, error) {
+	return c.Audience, nil
+}
+
+// Verifier checks token signatures against the configured keys and validates
+// the time and issuer claims
+type Verifier struct {
+	hmacSecrets   [][]byte
+	publicKeys    []crypto.PublicKey
+	issuer        string
+	audience      string
+	leeway        time.Duration
+	allowNoExpiry bool
+	clock         clock.Clock
+}
+
+// Option configures a Verifier
+type Option func(*Verifier)
+
+// WithHMACSecret accepts HS256, HS384 and HS512 tokens signed with secret.
+// Several secrets may be configured, e.g. while rotating them.
+func WithHMACSecret(secret []byte) Option {
+	return func(v *Verifier) {
+		v.hmacSecrets = append(v.hmacSecrets, secret)
+	}
+}
+
+// WithPublicKey accepts RS* tokens for an *rsa.PublicKey and ES* tokens for an
+// *ecdsa.PublicKey
+func WithPublicKey(key crypto.PublicKey) Option {
+	return func(v *Verifier) {
+		v.publicKeys = append(v.publicKeys, key)
+	}
+}
+
+// WithIssuer requires tokens to carry the given "iss" claim
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience requires tokens to name the given audience in their "aud" claim
+func WithAudience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway tolerates clock skew between the token issuer and the server
+func WithLeeway(d time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = d
+	}
+}
+
+// WithClock sets the clock the time claims are checked against
+func WithClock(c clock.Clock) Option {
+	return func(v *Verifier) {
+		v.clock = c
+	}
+}
+
+// AllowNoExpiry accepts tokens without an "exp" claim. They never expire, so
+// they are rejected unless the operator opts in.
+func AllowNoExpiry() Option {
+	return func(v *Verifier) {
+		v.allowNoExpiry = true
+	}
+}
+
+// NewVerifier creates a verifier. It rejects every token until a key is configured.
+func NewVerifier(opts ...Option) *Verifier {
+	v := &Verifier{clock: clock.Real}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// HasKeys reports whether any signing key is configured
+func (v *Verifier) HasKeys() bool {
+	return len(v.hmacSecrets) > 0 || len(v.publicKeys) > 0
+}
+
+// Verify checks a compact-serialized token and returns its claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	opts := []jwt.ParserOption{jwt.WithLeeway(v.leeway), jwt.WithTimeFunc(v.clock.Now)}
+	if !v.allowNoExpiry {
+		opts = append(opts, jwt.WithExpirationRequired())
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(token, &claims, v.keysFor, opts...); err != nil {
+		return nil, verifyError(err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidClaims)
+	}
+	return &claims, nil
+}
+
+// keysFor returns the configured keys that can check the token's signing
+// method. Any method but HS*, RS* and ES* is refused, "none" included.
+func (v *Verifier) keysFor(token *jwt.Token) (any, error) {
+	var keys jwt.VerificationKeySet
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		for _, secret := range v.hmacSecrets {
+			keys.Keys = append(keys.Keys, secret)
+		}
+	case *jwt.SigningMethodRSA:
+		for _, key := range v.publicKeys {
+			if rsaKey, ok := key.(*rsa.PublicKey); ok {
+				keys.Keys = append(keys.Keys, rsaKey)
+			}
+		}
+	case *jwt.SigningMethodECDSA:
+		for _, key := range v.publicKeys {
+			if ecKey, ok := key.(*ecdsa.PublicKey); ok {
+				keys.Keys = append(keys.Keys, ecKey)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, token.Method.Alg())
+	}
+	return keys, nil
+}
+
+// verifyError maps a rejection from the jwt package to the package's errors
+func verifyError(err error) error {
+	switch {
+	case errors.Is(err, ErrUnsupportedAlgorithm):
+		return ErrUnsupportedAlgorithm
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrMalformedToken
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrInvalidSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return ErrTokenNotYetValid
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalidClaims, err)
+	}
+}
+
+// SignHS256 issues an HMAC-signed token, e.g. for local testing
+func SignHS256(claims Claims, secret []byte) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, &claims).SignedString(secret)
+}
+
+// ParsePublicKeyPEM reads an RSA or ECDSA public key from a PEM "PUBLIC KEY",
+// "RSA PUBLIC KEY" or "CERTIFICATE" block
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+func numericDate(unix int64) *jwt.NumericDate {
+	if unix == 0 {
+		return nil
+	}
+	return jwt.NewNumericDate(time.Unix(unix, 0))
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+)
+
+var testSecret = []byte("test-secret")
+
+func validClaims(now time.Time) Claims {
+	return Claims{Subject: "alice", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+}
+
+// signWith builds a token for alg with a signature made by sign
+func signWith(t *testing.T, alg string, claims Claims, sign func(digest []byte) []byte) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign(digest[:]))
+}
+
+func TestVerify_HS256(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	v := NewVerifier(WithHMACSecret(testSecret), WithClock(clk))
+
+	token, err := SignHS256(validClaims(clk.Now()), testSecret)
+	if err != nil {
+		t.Fatalf("signing failed: %v", err)
+	}
+	claims, err := v.Verify(token)
+	if err != nil {
+		t.Fatalf("expected token to verify, got %v", err)
+	}
+	if claims.Subject != "alice" {
+		t.Errorf("expected subject alice, got %q", claims.Subject)
+	}
+
+	clk.Advance(time.Hour)
+	if _, err := v.Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired after an hour, got %v", err)
+	}
+}
+
+func TestVerify_RejectsBadTokens(t *testing.T) {
+	now := time.Now()
+	v := NewVerifier(WithHMACSecret(testSecret), WithIssuer("auth.example"), WithAudience("auction"))
+
+	sign := func(claims Claims, secret []byte) string {
+		token, err := SignHS256(claims, secret)
+		if err != nil {
+			t.Fatalf("signing failed: %v", err)
+		}
+		return token
+	}
+	good := validClaims(now)
+	good.Issuer, good.Audience = "auth.example", jwt.ClaimStrings{"auction"}
+
+	noSubject, wrongIssuer, wrongAudience, notYet, noExpiry := good, good, good, good, good
+	noSubject.Subject = ""
+	wrongIssuer.Issuer = "evil.example"
+	wrongAudience.Audience = jwt.ClaimStrings{"other"}
+	notYet.NotBefore = now.Add(time.Minute).Unix()
+	noExpiry.ExpiresAt = 0
+
+	unsigned := signWith(t, "none", good, func([]byte) []byte { return nil })
+
+	tokens := map[string]struct {
+		token string
+		want  error
+	}{
+		"wrong secret":   {sign(good, []byte("other")), ErrInvalidSignature},
+		"alg none":       {unsigned, ErrUnsupportedAlgorithm},
+		"garbage":        {"not.a.token", ErrMalformedToken},
+		"two segments":   {"a.b", ErrMalformedToken},
+		"no subject":     {sign(noSubject, testSecret), ErrInvalidClaims},
+		"wrong issuer":   {sign(wrongIssuer, testSecret), ErrInvalidClaims},
+		"wrong audience": {sign(wrongAudience, testSecret), ErrInvalidClaims},
+		"not yet valid":  {sign(notYet, testSecret), ErrTokenNotYetValid},
+		"no expiry":      {sign(noExpiry, testSecret), ErrInvalidClaims},
+	}
+	for name, tc := range tokens {
+		if _, err := v.Verify(tc.token); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+	if _, err := v.Verify(sign(good, testSecret)); err != nil {
+		t.Errorf("expected the good token to verify, got %v", err)
+	}
+}
+
+func TestVerify_AllowNoExpiry(t *testing.T) {
+	claims := validClaims(time.Now())
+	claims.ExpiresAt = 0
+	token, err := SignHS256(claims, testSecret)
+	if err != nil {
+		t.Fatalf("signing failed: %v", err)
+	}
+
+	if _, err := NewVerifier(WithHMACSecret(testSecret)).Verify(token); !errors.Is(err, ErrInvalidClaims) {
+		t.Errorf("expected a token without exp to be rejected, got %v", err)
+	}
+	if _, err := NewVerifier(WithHMACSecret(testSecret), AllowNoExpiry()).Verify(token); err != nil {
+		t.Errorf("expected a token without exp to verify when allowed, got %v", err)
+	}
+}
+
+func TestVerify_PublicKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier(WithPublicKey(&rsaKey.PublicKey), WithPublicKey(&ecKey.PublicKey))
+	claims := validClaims(time.Now())
+
+	rs256 := signWith(t, "RS256", claims, func(digest []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	})
+	es256 := signWith(t, "ES256", claims, func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	})
+
+	for alg, token := range map[string]string{"RS256": rs256, "ES256": es256} {
+		if _, err := v.Verify(token); err != nil {
+			t.Errorf("%s: expected token to verify, got %v", alg, err)
+		}
+	}
+
+	// An HMAC token must not verify just because public keys are configured
+	hs256, _ := SignHS256(claims, testSecret)
+	if _, err := v.Verify(hs256); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected HS256 to be rejected without a secret, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+func TestMiddleware(t *testing.T) {
+	v := NewVerifier(WithHMACSecret(testSecret))
+	var gotSubject string
+	var gotOK bool
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSubject, gotOK = SubjectFromContext(r.Context())
+	}))
+
+	token, _ := SignHS256(validClaims(time.Now()), testSecret)
+	cases := map[string]struct {
+		header      string
+		wantStatus  int
+		wantSubject string
+	}{
+		"valid token": {"Bearer " + token, http.StatusOK, "alice"},
+		"anonymous":   {"", http.StatusOK, ""},
+		"bad token":   {"Bearer nope", http.StatusUnauthorized, ""},
+	}
+	for name, tc := range cases {
+		gotSubject, gotOK = "", false
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tc.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", name, tc.wantStatus, rec.Code)
+		}
+		if gotSubject != tc.wantSubject || gotOK != (tc.wantSubject != "") {
+			t.Errorf("%s: expected subject %q, got %q", name, tc.wantSubject, gotSubject)
+		}
+	}
+}
+
+func TestWebsocketInit(t *testing.T) {
+	v := NewVerifier(WithHMACSecret(testSecret))
+	token, _ := SignHS256(validClaims(time.Now()), testSecret)
+
+	for _, payload := range []transport.InitPayload{
+		{"Authorization": "Bearer " + token},
+		{"authToken": token},
+	} {
+		ctx, _, err := v.WebsocketInit(context.Background(), payload)
+		if err != nil {
+			t.Fatalf("expected %v to be accepted, got %v", payload, err)
+		}
+		if sub, _ := SubjectFromContext(ctx); sub != "alice" {
+			t.Errorf("expected subject alice from %v, got %q", payload, sub)
+		}
+	}
+
+	ctx, _, err := v.WebsocketInit(context.Background(), transport.InitPayload{})
+	if err != nil {
+		t.Fatalf("expected an anonymous connection to be accepted, got %v", err)
+	}
+	if _, ok := SubjectFromContext(ctx); ok {
+		t.Error("expected no subject on an anonymous connection")
+	}
+
+	if _, _, err := v.WebsocketInit(context.Background(), transport.InitPayload{"authorization": "Bearer nope"}); !errors.Is(err, ErrMalformedToken) {
+		t.Errorf("expected a bad token to be refused, got %v", err)
+	}
+}
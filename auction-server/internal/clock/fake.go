@@ -0,0 +1,127 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Tickers and timers created
+// from it fire as Advance or Set carries the time past their deadlines.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	added   chan struct{}
+}
+
+// fakeWaiter is a pending ticker or timer. Timers have no period.
+type fakeWaiter struct {
+	clock   *Fake
+	next    time.Time
+	period  time.Duration
+	ch      chan time.Time
+	stopped bool
+}
+
+// NewFake creates a fake clock set to the given time
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, added: make(chan struct{}, 1)}
+}
+
+// Now returns the fake clock's current time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTicker returns a ticker that fires every d of fake time
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return f.addWaiter(d, d)
+}
+
+// After returns a channel that receives the fake time once d has passed
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.addWaiter(d, 0).ch
+}
+
+// Advance moves the clock forward by d, firing every ticker and timer that
+// falls due. Like a real ticker, a slow receiver misses ticks instead of
+// queueing them.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// Set moves the clock to t, firing every ticker and timer that falls due
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	f.fire()
+}
+
+// BlockUntil waits until at least n tickers and timers are pending, so a test
+// can be sure a goroutine is waiting on the clock before advancing it
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		pending := len(f.waiters)
+		f.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		<-f.added
+	}
+}
+
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{clock: f, next: f.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	f.fire()
+	select {
+	case f.added <- struct{}{}:
+	default:
+	}
+	return w
+}
+
+// fire delivers due ticks and drops finished waiters. Callers must hold mu.
+func (f *Fake) fire() {
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		for !w.stopped && !w.next.After(f.now) {
+			select {
+			case w.ch <- w.next:
+			default:
+			}
+			if w.period == 0 {
+				w.stopped = true
+				break
+			}
+			w.next = w.next.Add(w.period)
+		}
+		if !w.stopped {
+			pending = append(pending, w)
+		}
+	}
+	f.waiters = pending
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.ch }
+
+// Stop turns off the ticker
+func (w *fakeWaiter) Stop() {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	w.stopped = true
+	w.clock.fire()
+}
@@ -0,0 +1,54 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_TickerFiresOnAdvance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+	ticker := clk.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before the clock moved")
+	default:
+	}
+
+	// Ticks that nobody receives are dropped, like a real ticker
+	clk.Advance(3 * time.Second)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Second)) {
+		t.Errorf("expected first tick at %v, got %v", start.Add(time.Second), tick)
+	}
+	select {
+	case tick := <-ticker.C():
+		t.Errorf("expected missed ticks to be dropped, got %v", tick)
+	default:
+	}
+
+	clk.Advance(time.Second)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(4 * time.Second)) {
+		t.Errorf("expected tick at %v, got %v", start.Add(4*time.Second), tick)
+	}
+}
+
+func TestFake_After(t *testing.T) {
+	clk := NewFake(time.Now())
+	ch := clk.After(time.Minute)
+
+	clk.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	clk.Advance(time.Second)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("timer did not fire")
+	}
+}
@@ -0,0 +1,126 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Log is an append-only, file-backed log of auction state changes. Records are
+// stored one JSON object per line and numbered with a strictly increasing
+// sequence that continues across restarts.
+type Log struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	nextSeq uint64
+}
+
+// Open opens (or creates) the log at path. A partially written final line,
+// left behind by a crash mid-append, is discarded.
+func Open(path string) (*Log, error) {
+	records, validSize, err := read(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	if err := file.Truncate(validSize); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("truncate event log: %w", err)
+	}
+
+	l := &Log{path: path, file: file, nextSeq: 1}
+	if len(records) > 0 {
+		l.nextSeq = records[len(records)-1].Sequence + 1
+	}
+	return l, nil
+}
+
+// Append assigns the next sequence number to rec, writes it durably and
+// returns the stored record
+func (l *Log) Append(rec Record) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rec.Sequence = l.nextSeq
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return Record{}, fmt.Errorf("encode record: %w", err)
+	}
+	data = append(data, '\n')
+
+	if _, err := l.file.Write(data); err != nil {
+		return Record{}, fmt.Errorf("append record: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return Record{}, fmt.Errorf("sync event log: %w", err)
+	}
+
+	l.nextSeq++
+	return rec, nil
+}
+
+// Records returns every record written so far
+func (l *Log) Records() ([]Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ReadAll(l.path)
+}
+
+// Close closes the underlying file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// ReadAll reads every complete record from the log at path
+func ReadAll(path string) ([]Record, error) {
+	records, _, err := read(path)
+	return records, err
+}
+
+// read decodes the log at path and returns its records along with the byte
+// length of the well-formed prefix
+func read(path string) ([]Record, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var records []Record
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Anything after the last newline is an incomplete write
+			return records, offset, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("read event log: %w", err)
+		}
+
+		var rec Record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return nil, 0, fmt.Errorf("decode record at byte %d: %w", offset, err)
+		}
+		if len(records) > 0 && rec.Sequence <= records[len(records)-1].Sequence {
+			return nil, 0, fmt.Errorf("record at byte %d: sequence %d does not follow %d",
+				offset, rec.Sequence, records[len(records)-1].Sequence)
+		}
+
+		records = append(records, rec)
+		offset += int64(len(line))
+	}
+}
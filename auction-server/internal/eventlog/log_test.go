@@ -0,0 +1,136 @@
+package eventlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// usd returns a whole number of US dollars
+func usd(units int64) model.Money {
+	return model.WholeUnits(units, "USD")
+}
+
+func TestLog_SequenceContinuesAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := l.Append(NewAuctionEndedRecord("auction-1", time.Now(), model.AuctionStatusEnded)); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+	l.Close()
+
+	// Simulate a crash halfway through writing a record
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("reopen file failed: %v", err)
+	}
+	f.WriteString(`{"seq":3,"type":"AUC`)
+	f.Close()
+
+	l, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer l.Close()
+
+	rec, err := l.Append(NewAuctionEndedRecord("auction-1", time.Now(), model.AuctionStatusEnded))
+	if err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+	if rec.Sequence != 3 {
+		t.Errorf("expected sequence 3, got %d", rec.Sequence)
+	}
+
+	records, err := l.Records()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for i, r := range records {
+		if r.Sequence != uint64(i+1) {
+			t.Errorf("record %d: expected sequence %d, got %d", i, i+1, r.Sequence)
+		}
+	}
+}
+
+func TestReplay_RebuildsAuction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer l.Close()
+
+	now := time.Now()
+	auction := &model.Auction{
+		ID:          "auction-1",
+		StartingBid: usd(100),
+		CurrentBid:  usd(100),
+		Duration:    30,
+		StartTime:   now,
+		EndTime:     now.Add(30 * time.Second),
+		Status:      model.AuctionStatusActive,
+	}
+	extendedTo := now.Add(40 * time.Second)
+	for _, rec := range []Record{
+		NewAuctionCreatedRecord(auction),
+		NewBidAcceptedRecord(&model.Bid{ID: "bid-1", AuctionID: "auction-1", UserID: "user1", Amount: usd(150), Timestamp: now}),
+		NewBidAcceptedRecord(&model.Bid{ID: "bid-2", AuctionID: "auction-1", UserID: "user2", Amount: usd(175), Timestamp: now}),
+		NewAuctionExtendedRecord("auction-1", now, extendedTo),
+		NewAuctionEndedRecord("auction-1", extendedTo, model.AuctionStatusEnded),
+	} {
+		if _, err := l.Append(rec); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+
+	records, err := ReadAll(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	auctions, err := Replay(records)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if len(auctions) != 1 {
+		t.Fatalf("expected 1 auction, got %d", len(auctions))
+	}
+
+	rebuilt := auctions[0]
+	if rebuilt.Status != model.AuctionStatusEnded {
+		t.Errorf("expected status ENDED, got %s", rebuilt.Status)
+	}
+	if rebuilt.CurrentBid != usd(175) {
+		t.Errorf("expected current bid 175.0, got %s", rebuilt.CurrentBid)
+	}
+	if rebuilt.CurrentWinner == nil || *rebuilt.CurrentWinner != "user2" {
+		t.Errorf("expected winner user2, got %v", rebuilt.CurrentWinner)
+	}
+	if len(rebuilt.Bids) != 2 {
+		t.Errorf("expected 2 bids, got %d", len(rebuilt.Bids))
+	}
+	if !rebuilt.EndTime.Equal(extendedTo) {
+		t.Errorf("expected end time %v, got %v", extendedTo, rebuilt.EndTime)
+	}
+	if rebuilt.Extensions != 1 || !rebuilt.OriginalEndTime.Equal(auction.EndTime) {
+		t.Errorf("expected 1 extension from %v, got %d from %v", auction.EndTime, rebuilt.Extensions, rebuilt.OriginalEndTime)
+	}
+}
+
+func TestReplay_UnknownAuction(t *testing.T) {
+	_, err := Replay([]Record{{Sequence: 1, Type: RecordAuctionEnded, AuctionID: "auction-404"}})
+	if err == nil {
+		t.Error("expected error for record referencing an unknown auction")
+	}
+}
@@ -0,0 +1,186 @@
+package eventlog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// RecordType identifies the kind of state change a record describes
+type RecordType string
+
+const (
+	RecordAuctionCreated   RecordType = "AUCTION_CREATED"
+	RecordAuctionStarted   RecordType = "AUCTION_STARTED"
+	RecordBidAccepted      RecordType = "BID_ACCEPTED"
+	RecordMaxBidPlaced     RecordType = "MAX_BID_PLACED"
+	RecordAuctionExtended  RecordType = "AUCTION_EXTENDED"
+	RecordAuctionEnded     RecordType = "AUCTION_ENDED"
+	RecordPriceDropped     RecordType = "PRICE_DROPPED"
+	RecordSealedBidPlaced  RecordType = "SEALED_BID_PLACED"
+	RecordAuctionPaused    RecordType = "AUCTION_PAUSED"
+	RecordAuctionResumed   RecordType = "AUCTION_RESUMED"
+	RecordAuctionCancelled RecordType = "AUCTION_CANCELLED"
+	// RecordRolledBack cancels an earlier record whose change failed to apply
+	// after it was logged; replay skips both
+	RecordRolledBack RecordType = "RECORD_ROLLED_BACK"
+)
+
+// Record is a single entry in the event log. Only the fields relevant to the
+// record type are set.
+type Record struct {
+	Sequence  uint64              `json:"seq"`
+	Type      RecordType          `json:"type"`
+	AuctionID string              `json:"auctionId"`
+	Timestamp time.Time           `json:"timestamp"`
+	Auction   *model.Auction      `json:"auction,omitempty"`  // AUCTION_CREATED: initial state
+	Bid       *model.Bid          `json:"bid,omitempty"`      // BID_ACCEPTED, SEALED_BID_PLACED
+	ProxyBid  *model.ProxyBid     `json:"proxyBid,omitempty"` // MAX_BID_PLACED
+	EndTime   *time.Time          `json:"endTime,omitempty"`  // AUCTION_EXTENDED
+	Status    model.AuctionStatus `json:"status,omitempty"`   // AUCTION_ENDED
+	Price     *model.Money        `json:"price,omitempty"`    // PRICE_DROPPED: new asking price
+	NextDrop  *time.Time          `json:"nextDrop,omitempty"` // PRICE_DROPPED
+	Reverts   uint64              `json:"reverts,omitempty"`  // RECORD_ROLLED_BACK: sequence of the cancelled record
+}
+
+// NewAuctionCreatedRecord creates a record for a newly created auction
+func NewAuctionCreatedRecord(auction *model.Auction) Record {
+	snapshot := *auction
+	snapshot.Bids = nil
+	return Record{
+		Type:      RecordAuctionCreated,
+		AuctionID: auction.ID,
+		Timestamp: auction.StartTime,
+		Auction:   &snapshot,
+	}
+}
+
+// NewAuctionStartedRecord creates a record for a scheduled auction that opened
+func NewAuctionStartedRecord(auctionID string, at time.Time) Record {
+	return Record{
+		Type:      RecordAuctionStarted,
+		AuctionID: auctionID,
+		Timestamp: at,
+	}
+}
+
+// NewBidAcceptedRecord creates a record for a bid that became the leading bid
+func NewBidAcceptedRecord(bid *model.Bid) Record {
+	b := *bid
+	return Record{
+		Type:      RecordBidAccepted,
+		AuctionID: bid.AuctionID,
+		Timestamp: bid.Timestamp,
+		Bid:       &b,
+	}
+}
+
+// NewSealedBidPlacedRecord creates a record for a new or replaced sealed bid
+func NewSealedBidPlacedRecord(bid *model.Bid) Record {
+	rec := NewBidAcceptedRecord(bid)
+	rec.Type = RecordSealedBidPlaced
+	return rec
+}
+
+// NewMaxBidPlacedRecord creates a record for a bidder's new or raised proxy maximum
+func NewMaxBidPlacedRecord(auctionID string, proxy model.ProxyBid) Record {
+	return Record{
+		Type:      RecordMaxBidPlaced,
+		AuctionID: auctionID,
+		Timestamp: proxy.PlacedAt,
+		ProxyBid:  &proxy,
+	}
+}
+
+// NewAuctionExtendedRecord creates a record for an auction whose end time moved
+func NewAuctionExtendedRecord(auctionID string, at, endTime time.Time) Record {
+	return Record{
+		Type:      RecordAuctionExtended,
+		AuctionID: auctionID,
+		Timestamp: at,
+		EndTime:   &endTime,
+	}
+}
+
+// NewAuctionEndedRecord creates a record for an auction that closed with the given status
+func NewAuctionEndedRecord(auctionID string, at time.Time, status model.AuctionStatus) Record {
+	return Record{
+		Type:      RecordAuctionEnded,
+		AuctionID: auctionID,
+		Timestamp: at,
+		Status:    status,
+	}
+}
+
+// NewPriceDroppedRecord creates a record for a Dutch auction lowering its asking price
+func NewPriceDroppedRecord(auctionID string, at time.Time, price model.Money, nextDrop time.Time) Record {
+	return Record{
+		Type:      RecordPriceDropped,
+		AuctionID: auctionID,
+		Timestamp: at,
+		Price:     &price,
+		NextDrop:  &nextDrop,
+	}
+}
+
+// NewAuctionPausedRecord creates a record for an auction an operator paused
+func NewAuctionPausedRecord(auctionID string, at time.Time) Record {
+	return Record{Type: RecordAuctionPaused, AuctionID: auctionID, Timestamp: at}
+}
+
+// NewAuctionResumedRecord creates a record for a paused auction an operator resumed
+func NewAuctionResumedRecord(auctionID string, at time.Time) Record {
+	return Record{Type: RecordAuctionResumed, AuctionID: auctionID, Timestamp: at}
+}
+
+// NewAuctionCancelledRecord creates a record for an auction an operator cancelled
+func NewAuctionCancelledRecord(auctionID string, at time.Time) Record {
+	return Record{Type: RecordAuctionCancelled, AuctionID: auctionID, Timestamp: at}
+}
+
+// NewRolledBackRecord creates a record cancelling rec, which was logged but
+// whose change could not be applied
+func NewRolledBackRecord(rec Record, at time.Time) Record {
+	return Record{Type: RecordRolledBack, AuctionID: rec.AuctionID, Timestamp: at, Reverts: rec.Sequence}
+}
+
+// String renders a record as a single human-readable line
+func (r Record) String() string {
+	line := fmt.Sprintf("#%d %s %-16s %s", r.Sequence, r.Timestamp.Format(time.RFC3339Nano), r.Type, r.AuctionID)
+	switch r.Type {
+	case RecordAuctionCreated:
+		if r.Auction != nil {
+			line += fmt.Sprintf(" startingBid=%s duration=%ds extendedBidding=%t endTime=%s",
+				r.Auction.StartingBid, r.Auction.Duration, r.Auction.ExtendedBidding, r.Auction.EndTime.Format(time.RFC3339))
+			if r.Auction.Status == model.AuctionStatusPending {
+				line += fmt.Sprintf(" startTime=%s", r.Auction.StartTime.Format(time.RFC3339))
+			}
+			if r.Auction.ReservePrice != nil {
+				line += fmt.Sprintf(" reservePrice=%s", *r.Auction.ReservePrice)
+			}
+			if r.Auction.Type != "" {
+				line += fmt.Sprintf(" type=%s", r.Auction.Type)
+			}
+		}
+	case RecordBidAccepted, RecordSealedBidPlaced:
+		if r.Bid != nil {
+			line += fmt.Sprintf(" bid=%s user=%s amount=%s automatic=%t", r.Bid.ID, r.Bid.UserID, r.Bid.Amount, r.Bid.Automatic)
+		}
+	case RecordMaxBidPlaced:
+		if r.ProxyBid != nil {
+			line += fmt.Sprintf(" user=%s maxAmount=%s", r.ProxyBid.UserID, r.ProxyBid.MaxAmount)
+		}
+	case RecordAuctionExtended:
+		if r.EndTime != nil {
+			line += fmt.Sprintf(" endTime=%s", r.EndTime.Format(time.RFC3339))
+		}
+	case RecordAuctionEnded:
+		if r.Status != "" {
+			line += fmt.Sprintf(" status=%s", r.Status)
+		}
+	case RecordRolledBack:
+		line += fmt.Sprintf(" reverts=#%d", r.Reverts)
+	}
+	return line
+}
@@ -0,0 +1,126 @@
+package eventlog
+
+import (
+	"fmt"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// Apply applies a single record to the auction it belongs to
+func Apply(auction *model.Auction, rec Record) error {
+	switch rec.Type {
+	case RecordAuctionStarted:
+		auction.Status = model.AuctionStatusActive
+	case RecordBidAccepted:
+		if rec.Bid == nil {
+			return fmt.Errorf("record #%d: bid missing", rec.Sequence)
+		}
+		auction.ApplyBid(*rec.Bid)
+	case RecordSealedBidPlaced:
+		if rec.Bid == nil {
+			return fmt.Errorf("record #%d: bid missing", rec.Sequence)
+		}
+		auction.SetSealedBid(*rec.Bid)
+	case RecordMaxBidPlaced:
+		if rec.ProxyBid == nil {
+			return fmt.Errorf("record #%d: proxy bid missing", rec.Sequence)
+		}
+		auction.SetProxy(*rec.ProxyBid)
+	case RecordAuctionExtended:
+		if rec.EndTime == nil {
+			return fmt.Errorf("record #%d: end time missing", rec.Sequence)
+		}
+		auction.Extend(*rec.EndTime)
+	case RecordAuctionEnded:
+		status := rec.Status
+		if status == "" {
+			status = model.AuctionStatusEnded
+		}
+		auction.End(status)
+	case RecordAuctionPaused:
+		auction.Pause(rec.Timestamp)
+	case RecordAuctionResumed:
+		auction.Resume(rec.Timestamp)
+	case RecordAuctionCancelled:
+		auction.Cancel()
+	case RecordPriceDropped:
+		if rec.Price == nil || rec.NextDrop == nil || auction.DutchSchedule == nil {
+			return fmt.Errorf("record #%d: price drop missing or auction is not Dutch", rec.Sequence)
+		}
+		auction.CurrentBid = *rec.Price
+		schedule := *auction.DutchSchedule
+		schedule.NextDropAt = *rec.NextDrop
+		auction.DutchSchedule = &schedule
+	default:
+		return fmt.Errorf("record #%d: cannot apply %s to an existing auction", rec.Sequence, rec.Type)
+	}
+	return nil
+}
+
+// Replay rebuilds every auction from the log, returned in creation order.
+// Records that were rolled back are skipped.
+func Replay(records []Record) ([]*model.Auction, error) {
+	auctions := make(map[string]*model.Auction)
+	var order []*model.Auction
+
+	rolledBack := make(map[uint64]bool)
+	for _, rec := range records {
+		if rec.Type == RecordRolledBack {
+			rolledBack[rec.Reverts] = true
+		}
+	}
+
+	for _, rec := range records {
+		if rec.Type == RecordRolledBack || rolledBack[rec.Sequence] {
+			continue
+		}
+		if rec.Type == RecordAuctionCreated {
+			if rec.Auction == nil {
+				return nil, fmt.Errorf("record #%d: auction missing", rec.Sequence)
+			}
+			if _, exists := auctions[rec.AuctionID]; exists {
+				return nil, fmt.Errorf("record #%d: auction %s created twice", rec.Sequence, rec.AuctionID)
+			}
+			auction := *rec.Auction
+			auction.Bids = []model.Bid{}
+			if auction.Type == "" {
+				auction.Type = model.AuctionTypeEnglish // logged before auction types existed
+			}
+			if auction.Quantity == 0 {
+				auction.Quantity = 1 // logged before multi-unit lots existed
+			}
+			if auction.OriginalEndTime.IsZero() {
+				auction.OriginalEndTime = auction.EndTime // logged before extensions were tracked
+			}
+			auctions[rec.AuctionID] = &auction
+			order = append(order, &auction)
+			continue
+		}
+
+		auction, exists := auctions[rec.AuctionID]
+		if !exists {
+			return nil, fmt.Errorf("record #%d: unknown auction %s", rec.Sequence, rec.AuctionID)
+		}
+		if err := Apply(auction, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, auction := range order {
+		if auction.StartingBid.Currency == "" {
+			auction.SetCurrency(model.DefaultCurrency) // logged before amounts carried a currency
+		}
+	}
+	return order, nil
+}
+
+// History returns the records that belong to a single auction, in log order
+func History(records []Record, auctionID string) []Record {
+	var history []Record
+	for _, rec := range records {
+		if rec.AuctionID == auctionID {
+			history = append(history, rec)
+		}
+	}
+	return history
+}
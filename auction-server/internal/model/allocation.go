@@ -0,0 +1,92 @@
+package model
+
+import "sort"
+
+// PricingRule decides what each winner of a multi-unit lot pays per unit
+type PricingRule string
+
+const (
+	// PricingUniform charges every winner the lowest accepted bid
+	PricingUniform PricingRule = "UNIFORM"
+	// PricingPayAsBid charges every winner their own bid
+	PricingPayAsBid PricingRule = "PAY_AS_BID"
+)
+
+// Allocation is the number of units a bidder won in a multi-unit lot and the
+// price they pay per unit
+type Allocation struct {
+	UserID   string `json:"userId"`
+	BidID    string `json:"bidId"`
+	Quantity int    `json:"quantity"`
+	Price    Money  `json:"price"`
+}
+
+// IsMultiUnit returns true if the auction sells more than one unit
+func (a *Auction) IsMultiUnit() bool {
+	return a.Quantity > 1
+}
+
+// StandingBids returns each bidder's latest bid, highest first. Ties go to the
+// earlier bid.
+func (a *Auction) StandingBids() []Bid {
+	latest := make(map[string]int)
+	var standing []Bid
+	for _, b := range a.Bids {
+		if i, ok := latest[b.UserID]; ok {
+			standing[i] = b
+			continue
+		}
+		latest[b.UserID] = len(standing)
+		standing = append(standing, b)
+	}
+
+	sort.SliceStable(standing, func(i, j int) bool {
+		if c := standing[i].Amount.Cmp(standing[j].Amount); c != 0 {
+			return c > 0
+		}
+		return standing[i].Timestamp.Before(standing[j].Timestamp)
+	})
+	return standing
+}
+
+// Allocate hands out the lot's units to the highest standing bids. The lowest
+// accepted bid may be filled only partially, and bids below the reserve price
+// win nothing.
+func (a *Auction) Allocate() []Allocation {
+	remaining := a.Quantity
+	var allocations []Allocation
+	for _, b := range a.StandingBids() {
+		if remaining == 0 || (a.ReservePrice != nil && b.Amount.Cmp(*a.ReservePrice) < 0) {
+			break
+		}
+		units := min(b.Units(), remaining)
+		allocations = append(allocations, Allocation{
+			UserID:   b.UserID,
+			BidID:    b.ID,
+			Quantity: units,
+			Price:    b.Amount,
+		})
+		remaining -= units
+	}
+
+	if a.Pricing == PricingUniform && len(allocations) > 0 {
+		price := allocations[len(allocations)-1].Price
+		for i := range allocations {
+			allocations[i].Price = price
+		}
+	}
+	return allocations
+}
+
+// ClearingPrice returns the bid a newcomer has to beat: the lowest bid that
+// still wins units once every unit is spoken for, otherwise the starting bid
+func (a *Auction) ClearingPrice() Money {
+	remaining := a.Quantity
+	for _, b := range a.StandingBids() {
+		remaining -= b.Units()
+		if remaining <= 0 {
+			return b.Amount
+		}
+	}
+	return a.StartingBid
+}
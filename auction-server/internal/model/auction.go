@@ -10,66 +10,453 @@ const (
 	AuctionStatusActive  AuctionStatus = "ACTIVE"
 	AuctionStatusEnded   AuctionStatus = "ENDED"
 	AuctionStatusPending AuctionStatus = "PENDING"
+	// AuctionStatusReserveNotMet marks an auction that closed without a bid at
+	// or above its reserve price, so no winner was declared
+	AuctionStatusReserveNotMet AuctionStatus = "RESERVE_NOT_MET"
+	// AuctionStatusPaused marks an auction an operator froze; its remaining
+	// time is kept until it is resumed
+	AuctionStatusPaused AuctionStatus = "PAUSED"
+	// AuctionStatusCancelled marks an auction an operator called off; all of
+	// its bids are void
+	AuctionStatusCancelled AuctionStatus = "CANCELLED"
 )
 
+// AuctionType selects the auction mechanism
+type AuctionType string
+
+const (
+	// AuctionTypeEnglish is an ascending auction: the highest bid at close wins
+	AuctionTypeEnglish AuctionType = "ENGLISH"
+	// AuctionTypeDutch is a descending auction: the asking price drops on a
+	// schedule and the first bid at or above it wins immediately
+	AuctionTypeDutch AuctionType = "DUTCH"
+	// AuctionTypeSealedFirstPrice hides every bid until close; the highest
+	// bidder wins and pays their bid
+	AuctionTypeSealedFirstPrice AuctionType = "SEALED_FIRST_PRICE"
+	// AuctionTypeVickrey hides every bid until close; the highest bidder wins
+	// and pays the second-highest bid
+	AuctionTypeVickrey AuctionType = "VICKREY"
+)
+
+// DutchSchedule controls how the asking price of a Dutch auction falls
+type DutchSchedule struct {
+	DropAmount   Money     `json:"dropAmount"`
+	DropInterval int       `json:"dropInterval"` // seconds between drops
+	FloorPrice   Money     `json:"floorPrice"`   // the price never drops below this
+	NextDropAt   time.Time `json:"nextDropAt"`
+}
+
+// NextPrice returns the asking price after one more drop
+func (d *DutchSchedule) NextPrice(current Money) Money {
+	return MaxMoney(d.FloorPrice, current.Sub(d.DropAmount))
+}
+
 // Auction represents a live auction with all its properties
 type Auction struct {
-	ID              string        `json:"id"`
-	StartingBid     float64       `json:"startingBid"`
-	CurrentBid      float64       `json:"currentBid"`
-	CurrentWinner   *string       `json:"currentWinner"`
-	Duration        int           `json:"duration"`
-	ExtendedBidding bool          `json:"extendedBidding"`
-	StartTime       time.Time     `json:"startTime"`
-	EndTime         time.Time     `json:"endTime"`
-	Status          AuctionStatus `json:"status"`
-	Bids            []Bid         `json:"bids"`
-}
-
-// NextBid returns the minimum next valid bid amount
-func (a *Auction) NextBid() float64 {
-	return a.CurrentBid + 1.0
-}
-
-// TimeRemaining returns the number of seconds remaining in the auction
-func (a *Auction) TimeRemaining() int {
-	if a.Status != AuctionStatusActive {
+	ID              string            `json:"id"`
+	Type            AuctionType       `json:"type"`
+	SellerID        string            `json:"sellerId,omitempty"` // user who created the auction
+	StartingBid     Money             `json:"startingBid"`
+	CurrentBid      Money             `json:"currentBid"`
+	CurrentWinner   *string           `json:"currentWinner"`
+	Duration        int               `json:"duration"`
+	ExtendedBidding bool              `json:"extendedBidding"`
+	ExtensionPolicy *ExtensionPolicy  `json:"extensionPolicy,omitempty"` // how late bids extend the auction; nil for the default
+	Extensions      int               `json:"extensions"`                // number of times the auction was extended
+	Increments      IncrementSchedule `json:"increments,omitempty"`      // minimum raise per price band
+	ReservePrice    *Money            `json:"reservePrice,omitempty"`    // never exposed through the API
+	DutchSchedule   *DutchSchedule    `json:"dutchSchedule,omitempty"`   // set for Dutch auctions only
+	BuyNowPrice     *Money            `json:"buyNowPrice,omitempty"`     // price at which a bidder can end the auction at once
+	PausedAt        *time.Time        `json:"pausedAt,omitempty"`        // set while the auction is paused
+	StartTime       time.Time         `json:"startTime"`
+	EndTime         time.Time         `json:"endTime"`
+	OriginalEndTime time.Time         `json:"originalEndTime"` // end time before any extension
+	Status          AuctionStatus     `json:"status"`
+	Bids            []Bid             `json:"bids"`
+	ProxyBids       []ProxyBid        `json:"proxyBids,omitempty"`
+	SealedBids      []Bid             `json:"sealedBids,omitempty"`  // hidden until the auction closes
+	ReplaceableBids bool              `json:"replaceableBids"`       // sealed auctions: a bidder may replace their bid
+	Quantity        int               `json:"quantity"`              // units in the lot
+	Pricing         PricingRule       `json:"pricing,omitempty"`     // multi-unit lots only
+	Allocations     []Allocation      `json:"allocations,omitempty"` // set when a multi-unit lot ends
+}
+
+// NextBid returns the minimum next valid bid amount. For Dutch auctions this
+// is the current asking price.
+func (a *Auction) NextBid() Money {
+	if a.Type == AuctionTypeDutch {
+		return a.CurrentBid
+	}
+	if a.IsSealed() {
+		return a.StartingBid
+	}
+	return a.Increments.Next(a.CurrentBid)
+}
+
+// Public returns a copy of the auction without what the GraphQL API keeps from
+// bidders: the reserve price, the bid history, proxy maximums and sealed bids.
+// Every user ID goes through redact.
+func (a *Auction) Public(redact func(userID string) string) *Auction {
+	public := *a
+	public.ReservePrice = nil
+	public.Bids = nil
+	public.ProxyBids = nil
+	public.SealedBids = nil
+	if a.CurrentWinner != nil {
+		winner := redact(*a.CurrentWinner)
+		public.CurrentWinner = &winner
+	}
+	if a.Allocations != nil {
+		public.Allocations = make([]Allocation, len(a.Allocations))
+		for i, allocation := range a.Allocations {
+			allocation.UserID = redact(allocation.UserID)
+			public.Allocations[i] = allocation
+		}
+	}
+	return &public
+}
+
+// Snapshot returns a copy of the auction as it stands, for events that must
+// keep showing it that way. Slices and pointed-to values are shared, since
+// changes replace them rather than writing to them in place.
+func (a *Auction) Snapshot() *Auction {
+	snapshot := *a
+	return &snapshot
+}
+
+// TimeRemaining returns the number of seconds remaining in the auction at the
+// given time. A paused auction reports the time it had left when it was paused.
+func (a *Auction) TimeRemaining(now time.Time) int {
+	switch {
+	case a.Status == AuctionStatusPaused && a.PausedAt != nil:
+		now = *a.PausedAt
+	case a.Status != AuctionStatusActive:
 		return 0
 	}
-	
-	remaining := a.EndTime.Sub(time.Now())
+
+	remaining := a.EndTime.Sub(now)
 	if remaining < 0 {
 		return 0
 	}
-	
+
 	return int(remaining.Seconds())
 }
 
-// IsActive checks if the auction is currently active
-func (a *Auction) IsActive() bool {
-	return a.Status == AuctionStatusActive && time.Now().Before(a.EndTime)
+// IsActive checks if the auction is active at the given time
+func (a *Auction) IsActive(now time.Time) bool {
+	return a.Status == AuctionStatusActive && now.Before(a.EndTime)
+}
+
+// Currency returns the currency every amount in the auction is in
+func (a *Auction) Currency() string {
+	if a.StartingBid.Currency == "" {
+		return DefaultCurrency
+	}
+	return a.StartingBid.Currency
+}
+
+// SetCurrency ties every amount in the auction to its currency. It is used for
+// auctions saved before amounts carried one.
+func (a *Auction) SetCurrency(currency string) {
+	set := func(m *Money) { m.Currency = currency }
+	set(&a.StartingBid)
+	set(&a.CurrentBid)
+	if a.ReservePrice != nil {
+		reserve := *a.ReservePrice
+		set(&reserve)
+		a.ReservePrice = &reserve
+	}
+	if a.BuyNowPrice != nil {
+		buyNow := *a.BuyNowPrice
+		set(&buyNow)
+		a.BuyNowPrice = &buyNow
+	}
+	if a.DutchSchedule != nil {
+		schedule := *a.DutchSchedule
+		set(&schedule.DropAmount)
+		set(&schedule.FloorPrice)
+		a.DutchSchedule = &schedule
+	}
+	if a.Increments != nil {
+		increments := make(IncrementSchedule, len(a.Increments))
+		for i, tier := range a.Increments {
+			set(&tier.Increment)
+			if tier.UpTo != nil {
+				upTo := *tier.UpTo
+				set(&upTo)
+				tier.UpTo = &upTo
+			}
+			increments[i] = tier
+		}
+		a.Increments = increments
+	}
+	a.Bids = withCurrency(a.Bids, currency)
+	a.SealedBids = withCurrency(a.SealedBids, currency)
+	if a.ProxyBids != nil {
+		proxies := make([]ProxyBid, len(a.ProxyBids))
+		for i, p := range a.ProxyBids {
+			set(&p.MaxAmount)
+			proxies[i] = p
+		}
+		a.ProxyBids = proxies
+	}
+	if a.Allocations != nil {
+		allocations := make([]Allocation, len(a.Allocations))
+		for i, al := range a.Allocations {
+			set(&al.Price)
+			allocations[i] = al
+		}
+		a.Allocations = allocations
+	}
+}
+
+// withCurrency returns a copy of the bids with their amounts in currency
+func withCurrency(bids []Bid, currency string) []Bid {
+	if bids == nil {
+		return nil
+	}
+	updated := make([]Bid, len(bids))
+	for i, b := range bids {
+		b.Amount.Currency = currency
+		updated[i] = b
+	}
+	return updated
+}
+
+// HasReserve returns true if the auction has a reserve price
+func (a *Auction) HasReserve() bool {
+	return a.ReservePrice != nil
+}
+
+// ReserveMet returns true if the leading bid has reached the reserve price.
+// Auctions without a reserve always meet it.
+func (a *Auction) ReserveMet() bool {
+	if a.ReservePrice == nil {
+		return true
+	}
+	if a.IsMultiUnit() {
+		return len(a.Allocate()) > 0
+	}
+	return a.HasBids() && a.CurrentBid.Cmp(*a.ReservePrice) >= 0
+}
+
+// EndStatus returns the status the auction should close with
+func (a *Auction) EndStatus() AuctionStatus {
+	if a.IsSealed() && a.ReservePrice != nil {
+		if winner, _ := a.SealedResult(); winner == nil || winner.Amount.Cmp(*a.ReservePrice) < 0 {
+			return AuctionStatusReserveNotMet
+		}
+		return AuctionStatusEnded
+	}
+	if !a.ReserveMet() {
+		return AuctionStatusReserveNotMet
+	}
+	return AuctionStatusEnded
+}
+
+// End closes the auction with the given status. An auction that didn't meet its
+// reserve keeps its bids but has no winner. Sealed bids are revealed here.
+func (a *Auction) End(status AuctionStatus) {
+	if a.IsSealed() && a.isOpen() {
+		if winner, price := a.SealedResult(); winner != nil {
+			a.CurrentWinner = &winner.UserID
+			a.CurrentBid = price
+		}
+	}
+	if a.IsMultiUnit() && a.isOpen() {
+		a.Allocations = a.Allocate()
+	}
+	a.Status = status
+	a.PausedAt = nil
+	if status == AuctionStatusReserveNotMet {
+		a.CurrentWinner = nil
+	}
+}
+
+// isOpen returns true while the auction has not been decided: it is active or paused
+func (a *Auction) isOpen() bool {
+	return a.Status == AuctionStatusActive || a.Status == AuctionStatusPaused
+}
+
+// Pause freezes an active auction at the given time
+func (a *Auction) Pause(at time.Time) {
+	a.Status = AuctionStatusPaused
+	a.PausedAt = &at
+}
+
+// Resume reopens a paused auction. The end time and any pending Dutch price
+// drop move back by however long the auction was paused.
+func (a *Auction) Resume(at time.Time) {
+	if a.PausedAt != nil {
+		paused := at.Sub(*a.PausedAt)
+		a.EndTime = a.EndTime.Add(paused)
+		if !a.OriginalEndTime.IsZero() {
+			a.OriginalEndTime = a.OriginalEndTime.Add(paused)
+		}
+		if a.DutchSchedule != nil {
+			schedule := *a.DutchSchedule
+			schedule.NextDropAt = schedule.NextDropAt.Add(paused)
+			a.DutchSchedule = &schedule
+		}
+	}
+	a.Status = AuctionStatusActive
+	a.PausedAt = nil
+}
+
+// Cancel calls the auction off. Its bids stay on record but are void: nobody
+// wins and the current bid falls back to the starting bid.
+func (a *Auction) Cancel() {
+	a.Status = AuctionStatusCancelled
+	a.PausedAt = nil
+	a.CurrentBid = a.StartingBid
+	a.CurrentWinner = nil
+	a.Allocations = nil
+}
+
+// ApplyBid appends an accepted bid and makes it the leading bid. A multi-unit
+// lot has no single leader; its CurrentBid becomes the clearing price.
+func (a *Auction) ApplyBid(bid Bid) {
+	a.Bids = append(a.Bids, bid)
+	if a.IsMultiUnit() {
+		a.CurrentBid = a.ClearingPrice()
+		return
+	}
+	a.CurrentBid = bid.Amount
+	a.CurrentWinner = &bid.UserID
 }
 
-// HasBids returns true if at least one bid has been placed
+// HasBids returns true if at least one bid has been placed. Sealed bids only
+// count once the auction has closed.
 func (a *Auction) HasBids() bool {
+	if a.IsSealed() {
+		return !a.isOpen() && len(a.SealedBids) > 0
+	}
 	return len(a.Bids) > 0
 }
 
-// HighestBid returns the highest bid placed, or nil if no bids exist
+// HighestBid returns the highest bid placed, or nil if no bids exist. On a
+// sealed auction that is the winning sealed bid, once the auction has closed.
 func (a *Auction) HighestBid() *Bid {
-	if !a.HasBids() {
+	if a.IsSealed() {
+		if !a.HasBids() {
+			return nil
+		}
+		winner, _ := a.SealedResult()
+		return winner
+	}
+	if len(a.Bids) == 0 {
 		return nil
 	}
 	return &a.Bids[len(a.Bids)-1]
 }
 
-// ShouldExtend determines if the auction should be extended based on
-// the extended bidding rules and current time
-func (a *Auction) ShouldExtend() bool {
+// ProxyFor returns the proxy bid of the given user, or nil if they have none
+func (a *Auction) ProxyFor(userID string) *ProxyBid {
+	for i := range a.ProxyBids {
+		if a.ProxyBids[i].UserID == userID {
+			return &a.ProxyBids[i]
+		}
+	}
+	return nil
+}
+
+// SetProxy adds a proxy bid or replaces the user's existing one. The slice is
+// rebuilt rather than modified in place so copies of the auction are unaffected.
+func (a *Auction) SetProxy(proxy ProxyBid) {
+	proxies := make([]ProxyBid, 0, len(a.ProxyBids)+1)
+	for _, p := range a.ProxyBids {
+		if p.UserID != proxy.UserID {
+			proxies = append(proxies, p)
+		}
+	}
+	a.ProxyBids = append(proxies, proxy)
+}
+
+// IsSealed returns true for auctions whose bids stay hidden until close
+func (a *Auction) IsSealed() bool {
+	return a.Type == AuctionTypeSealedFirstPrice || a.Type == AuctionTypeVickrey
+}
+
+// SealedBidFor returns the sealed bid of the given user, or nil if they have none
+func (a *Auction) SealedBidFor(userID string) *Bid {
+	for i := range a.SealedBids {
+		if a.SealedBids[i].UserID == userID {
+			return &a.SealedBids[i]
+		}
+	}
+	return nil
+}
+
+// SetSealedBid adds a sealed bid or replaces the user's existing one. Like
+// SetProxy, the slice is rebuilt so copies of the auction are unaffected.
+func (a *Auction) SetSealedBid(bid Bid) {
+	bids := make([]Bid, 0, len(a.SealedBids)+1)
+	for _, b := range a.SealedBids {
+		if b.UserID != bid.UserID {
+			bids = append(bids, b)
+		}
+	}
+	a.SealedBids = append(bids, bid)
+}
+
+// SealedResult returns the winning sealed bid and the price the winner pays,
+// or nil if nobody bid. Ties go to the earlier bid. In a Vickrey auction the
+// price is the second-highest bid, or the starting bid without competition,
+// raised to the reserve if there is one.
+func (a *Auction) SealedResult() (*Bid, Money) {
+	var first, second *Bid
+	for i := range a.SealedBids {
+		b := &a.SealedBids[i]
+		switch {
+		case first == nil || b.Amount.Cmp(first.Amount) > 0 ||
+			(b.Amount.Cmp(first.Amount) == 0 && b.Timestamp.Before(first.Timestamp)):
+			first, second = b, first
+		case second == nil || b.Amount.Cmp(second.Amount) > 0:
+			second = b
+		}
+	}
+	if first == nil {
+		return nil, Money{}
+	}
+	if a.Type != AuctionTypeVickrey {
+		return first, first.Amount
+	}
+
+	price := a.StartingBid
+	if second != nil {
+		price = second.Amount
+	}
+	if a.ReservePrice != nil {
+		price = MaxMoney(price, *a.ReservePrice)
+	}
+	return first, MinMoney(price, first.Amount)
+}
+
+// ExtendedEndTime returns the end time a bid at now moves the auction to under
+// its extension policy, or false if the bid doesn't extend it. Auctions with
+// extended bidding but no policy of their own use the default one.
+func (a *Auction) ExtendedEndTime(now time.Time) (time.Time, bool) {
 	if !a.ExtendedBidding {
-		return false
+		return time.Time{}, false
+	}
+	policy := DefaultExtensionPolicy()
+	if a.ExtensionPolicy != nil {
+		policy = *a.ExtensionPolicy
 	}
-	
-	timeRemaining := a.EndTime.Sub(time.Now())
-	return timeRemaining < 10*time.Second
-}
\ No newline at end of file
+	return policy.ExtendedEndTime(a.EndTime, a.OriginalEnd(), now, a.Extensions)
+}
+
+// Extend moves the end of the auction to endTime and counts the extension
+func (a *Auction) Extend(endTime time.Time) {
+	a.EndTime = endTime
+	a.Extensions++
+}
+
+// OriginalEnd returns when the auction was due to end before any extension.
+// Auctions saved before this was tracked report their current end time.
+func (a *Auction) OriginalEnd() time.Time {
+	if a.OriginalEndTime.IsZero() {
+		return a.EndTime
+	}
+	return a.OriginalEndTime
+}
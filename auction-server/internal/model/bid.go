@@ -7,13 +7,40 @@ type Bid struct {
 	ID        string    `json:"id"`
 	AuctionID string    `json:"auctionId"`
 	UserID    string    `json:"userId"`
-	Amount    float64   `json:"amount"`
+	Amount    Money     `json:"amount"`
+	Quantity  int       `json:"quantity"`  // units wanted at Amount each
+	Automatic bool      `json:"automatic"` // placed by the server on behalf of a proxy bid
+	Sealed    bool      `json:"sealed"`    // amount withheld until the auction closes
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// ProxyBid is a bidder's hidden maximum. The server bids on their behalf, one
+// increment at a time, until MaxAmount is reached. It must never be exposed to
+// other bidders.
+type ProxyBid struct {
+	UserID    string    `json:"userId"`
+	MaxAmount Money     `json:"maxAmount"`
+	PlacedAt  time.Time `json:"placedAt"`
+}
+
+// Masked returns a copy of a sealed bid that is safe to broadcast: the
+// amount is withheld
+func (b *Bid) Masked() *Bid {
+	masked := *b
+	masked.Amount = Money{Currency: b.Amount.Currency}
+	masked.Sealed = true
+	return &masked
+}
+
+// Units returns the number of units the bid is for. Bids placed before
+// multi-unit lots existed count as one.
+func (b *Bid) Units() int {
+	return max(1, b.Quantity)
+}
+
 // IsHigherThan checks if this bid amount is higher than the given amount
-func (b *Bid) IsHigherThan(amount float64) bool {
-	return b.Amount > amount
+func (b *Bid) IsHigherThan(amount Money) bool {
+	return b.Amount.Cmp(amount) > 0
 }
 
 // IsPlacedBy checks if this bid was placed by the given user
@@ -21,7 +48,7 @@ func (b *Bid) IsPlacedBy(userID string) bool {
 	return b.UserID == userID
 }
 
-// Age returns the duration since this bid was placed
-func (b *Bid) Age() time.Duration {
-	return time.Since(b.Timestamp)
+// Age returns the duration between the bid being placed and the given time
+func (b *Bid) Age(now time.Time) time.Duration {
+	return now.Sub(b.Timestamp)
 }
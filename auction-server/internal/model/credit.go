@@ -0,0 +1,118 @@
+package model
+
+import "time"
+
+// Hold is credit a bidder has committed to an auction they may still win
+type Hold struct {
+	AuctionID string    `json:"auctionId"`
+	Amount    Money     `json:"amount"`
+	PlacedAt  time.Time `json:"placedAt"`
+}
+
+// Settlement records what a winner was charged when an auction ended
+type Settlement struct {
+	AuctionID string    `json:"auctionId"`
+	Amount    Money     `json:"amount"`
+	SettledAt time.Time `json:"settledAt"`
+}
+
+// Balance returns the user's credit in the given currency
+func (u *User) Balance(currency string) Money {
+	for _, c := range u.Credit {
+		if c.Currency == currency {
+			return c
+		}
+	}
+	return Money{Currency: currency}
+}
+
+// Available returns the credit in the given currency that isn't held for any auction
+func (u *User) Available(currency string) Money {
+	available := u.Balance(currency)
+	for _, h := range u.Holds {
+		if h.Amount.Currency == currency {
+			available = available.Sub(h.Amount)
+		}
+	}
+	return available
+}
+
+// HoldOn returns the user's hold on an auction, or zero if they have none
+func (u *User) HoldOn(auctionID string) Money {
+	for _, h := range u.Holds {
+		if h.AuctionID == auctionID {
+			return h.Amount
+		}
+	}
+	return Money{}
+}
+
+// SetHold replaces the user's hold on an auction; a zero amount releases it
+func (u *User) SetHold(auctionID string, amount Money, at time.Time) {
+	holds := make([]Hold, 0, len(u.Holds)+1)
+	for _, h := range u.Holds {
+		if h.AuctionID != auctionID {
+			holds = append(holds, h)
+		}
+	}
+	if amount.IsPositive() {
+		holds = append(holds, Hold{AuctionID: auctionID, Amount: amount, PlacedAt: at})
+	}
+	u.Holds = holds
+}
+
+// Deposit adds to the user's credit
+func (u *User) Deposit(amount Money) error {
+	balance, err := u.Balance(amount.Currency).Add(amount)
+	if err != nil {
+		return err
+	}
+	u.Credit = u.withBalance(balance)
+	return nil
+}
+
+// Settle releases the user's hold on an auction they won and charges them amount
+func (u *User) Settle(auctionID string, amount Money, at time.Time) {
+	u.SetHold(auctionID, Money{}, at)
+	u.Credit = u.withBalance(u.Balance(amount.Currency).Sub(amount))
+	settlements := make([]Settlement, len(u.Settlements), len(u.Settlements)+1)
+	copy(settlements, u.Settlements)
+	u.Settlements = append(settlements, Settlement{AuctionID: auctionID, Amount: amount, SettledAt: at})
+}
+
+// withBalance returns a copy of the user's credit with balance replacing the
+// entry in its currency
+func (u *User) withBalance(balance Money) []Money {
+	credit := make([]Money, 0, len(u.Credit)+1)
+	for _, c := range u.Credit {
+		if c.Currency != balance.Currency {
+			credit = append(credit, c)
+		}
+	}
+	return append(credit, balance)
+}
+
+// Charges returns what each winner of an ended auction owes: the final price,
+// or for a multi-unit lot the price of every unit they were allocated
+func (a *Auction) Charges() (map[string]Money, error) {
+	charges := make(map[string]Money)
+	if a.Status != AuctionStatusEnded {
+		return charges, nil
+	}
+	if a.IsMultiUnit() {
+		for _, al := range a.Allocations {
+			cost, err := al.Price.Times(al.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			if charges[al.UserID], err = charges[al.UserID].Add(cost); err != nil {
+				return nil, err
+			}
+		}
+		return charges, nil
+	}
+	if a.CurrentWinner != nil {
+		charges[*a.CurrentWinner] = a.CurrentBid
+	}
+	return charges, nil
+}
@@ -1,24 +1,59 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Common errors used throughout the auction system
 var (
-	ErrAuctionAlreadyActive = errors.New("an auction is already active")
-	ErrNoActiveAuction      = errors.New("no active auction")
-	ErrBidTooLow            = errors.New("bid too low")
-	ErrBidTooLate           = errors.New("bid too late")
-	ErrInvalidBidAmount     = errors.New("invalid bid amount")
-	ErrInvalidDuration      = errors.New("invalid auction duration")
-	ErrInvalidStartingBid   = errors.New("invalid starting bid")
-	ErrAuctionNotFound      = errors.New("auction not found")
+	ErrNoActiveAuction        = errors.New("no active auction")
+	ErrBidTooLow              = errors.New("bid too low")
+	ErrBidTooLate             = errors.New("bid too late")
+	ErrInvalidBidAmount       = errors.New("invalid bid amount")
+	ErrInvalidDuration        = errors.New("invalid auction duration")
+	ErrInvalidStartingBid     = errors.New("invalid starting bid")
+	ErrInvalidReservePrice    = errors.New("invalid reserve price")
+	ErrAuctionNotFound        = errors.New("auction not found")
+	ErrInvalidAuctionType     = errors.New("invalid auction type")
+	ErrInvalidPriceDrop       = errors.New("invalid price drop schedule")
+	ErrUnsupportedForType     = errors.New("not supported for this auction type")
+	ErrAlreadyBid             = errors.New("bid already placed")
+	ErrInvalidQuantity        = errors.New("invalid quantity")
+	ErrInvalidPricingRule     = errors.New("invalid pricing rule")
+	ErrInvalidBuyNowPrice     = errors.New("invalid buy-now price")
+	ErrBuyNowUnavailable      = errors.New("buy-now is not available")
+	ErrAuctionNotStarted      = errors.New("auction has not started yet")
+	ErrAuctionPaused          = errors.New("auction is paused")
+	ErrInvalidStatusChange    = errors.New("auction cannot change to the requested status")
+	ErrInvalidMoney           = errors.New("invalid amount")
+	ErrInvalidCurrency        = errors.New("invalid currency code")
+	ErrCurrencyMismatch       = errors.New("amount is in a different currency")
+	ErrInvalidIncrements      = errors.New("invalid bid increment schedule")
+	ErrInvalidExtensionPolicy = errors.New("invalid extension policy")
+	ErrUnauthenticated        = errors.New("authentication required")
+	ErrForbidden              = errors.New("not permitted for your role")
+	ErrNotAuctionSeller       = errors.New("auction belongs to another seller")
+	ErrUnknownBidder          = errors.New("bidder is not registered")
+	ErrBidderNotVerified      = errors.New("bidder is not verified")
+	ErrBidderSuspended        = errors.New("bidder is suspended")
+	ErrAlreadyRegistered      = errors.New("bidder is already registered")
+	ErrInvalidDisplayName     = errors.New("invalid display name")
+	ErrInsufficientCredit     = errors.New("insufficient credit")
+	ErrInvalidIdempotencyKey  = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used for a different bid")
+	ErrInvalidWebhookURL      = errors.New("invalid webhook URL")
+	ErrInvalidWebhookEvents   = errors.New("invalid webhook event types")
+	ErrInvalidWebhookSecret   = errors.New("invalid webhook secret")
+	ErrWebhookNotFound        = errors.New("webhook not found")
+	ErrDeliveryNotFound       = errors.New("webhook delivery not found")
 )
 
 // BidError represents a bid-specific error with context
 type BidError struct {
 	Err           error
-	CurrentBid    float64
-	AttemptedBid  float64
+	CurrentBid    Money
+	AttemptedBid  Money
 	TimeRemaining int
 }
 
@@ -31,7 +66,7 @@ func (e *BidError) Unwrap() error {
 }
 
 // NewBidTooLowError creates a detailed bid too low error
-func NewBidTooLowError(currentBid, attemptedBid float64) *BidError {
+func NewBidTooLowError(currentBid, attemptedBid Money) *BidError {
 	return &BidError{
 		Err:          ErrBidTooLow,
 		CurrentBid:   currentBid,
@@ -45,3 +80,46 @@ func NewBidTooLateError() *BidError {
 		Err: ErrBidTooLate,
 	}
 }
+
+// BidderError is why a user may not bid, with the user it concerns
+type BidderError struct {
+	Err    error
+	UserID string
+	Reason string // why the user was suspended, if given
+}
+
+func (e *BidderError) Error() string {
+	if e.Reason != "" {
+		return e.Err.Error() + ": " + e.Reason
+	}
+	return e.Err.Error()
+}
+
+func (e *BidderError) Unwrap() error {
+	return e.Err
+}
+
+// NewUnknownBidderError creates an error for a user who never registered
+func NewUnknownBidderError(userID string) *BidderError {
+	return &BidderError{Err: ErrUnknownBidder, UserID: userID}
+}
+
+// NewBidderSuspendedError creates an error for a suspended user
+func NewBidderSuspendedError(userID, reason string) *BidderError {
+	return &BidderError{Err: ErrBidderSuspended, UserID: userID, Reason: reason}
+}
+
+// CreditError is a bid the user's available credit doesn't cover
+type CreditError struct {
+	UserID    string
+	Required  Money
+	Available Money
+}
+
+func (e *CreditError) Error() string {
+	return fmt.Sprintf("%s: %s required, %s available", ErrInsufficientCredit, e.Required, e.Available)
+}
+
+func (e *CreditError) Unwrap() error {
+	return ErrInsufficientCredit
+}
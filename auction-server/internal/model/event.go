@@ -7,14 +7,39 @@ const (
 	EventAuctionStarted AuctionEventType = "AUCTION_STARTED"
 	EventBidPlaced      AuctionEventType = "BID_PLACED"
 	EventAuctionEnded   AuctionEventType = "AUCTION_ENDED"
+	EventPriceDropped   AuctionEventType = "PRICE_DROPPED"
+	// EventAuctionExtended follows the BID_PLACED of a late bid that pushed
+	// the end time back
+	EventAuctionExtended AuctionEventType = "AUCTION_EXTENDED"
+	// Operator actions
+	EventAuctionCancelled  AuctionEventType = "AUCTION_CANCELLED"
+	EventAuctionPaused     AuctionEventType = "AUCTION_PAUSED"
+	EventAuctionResumed    AuctionEventType = "AUCTION_RESUMED"
+	EventAuctionForceEnded AuctionEventType = "AUCTION_FORCE_ENDED"
+	// EventResyncRequired tells a resuming subscriber that events it missed are
+	// no longer buffered and it must refetch the auction state
+	EventResyncRequired AuctionEventType = "RESYNC_REQUIRED"
 )
 
-// AuctionEvent represents an event that occurred in the auction system
+// IsBroadcast reports whether events of this type go out to every subscriber,
+// as opposed to RESYNC_REQUIRED, which is only ever sent to one
+func (t AuctionEventType) IsBroadcast() bool {
+	switch t {
+	case EventAuctionStarted, EventBidPlaced, EventAuctionEnded, EventPriceDropped, EventAuctionExtended,
+		EventAuctionCancelled, EventAuctionPaused, EventAuctionResumed, EventAuctionForceEnded:
+		return true
+	}
+	return false
+}
+
+// AuctionEvent represents an event that occurred in the auction system.
+// Sequence numbers increase by one per event within a single auction.
 type AuctionEvent struct {
-	Type    AuctionEventType `json:"type"`
-	Auction *Auction         `json:"auction,omitempty"`
-	Bid     *Bid             `json:"bid,omitempty"`
-	Error   *string          `json:"error,omitempty"`
+	Sequence int              `json:"sequence"`
+	Type     AuctionEventType `json:"type"`
+	Auction  *Auction         `json:"auction,omitempty"`
+	Bid      *Bid             `json:"bid,omitempty"`
+	Error    *string          `json:"error,omitempty"`
 }
 
 // NewAuctionStartedEvent creates an event for when an auction starts
@@ -42,6 +67,64 @@ func NewAuctionEndedEvent(auction *Auction) *AuctionEvent {
 	}
 }
 
+// NewAuctionExtendedEvent creates an event for when a late bid extends an auction
+func NewAuctionExtendedEvent(auction *Auction) *AuctionEvent {
+	return &AuctionEvent{
+		Type:    EventAuctionExtended,
+		Auction: auction,
+	}
+}
+
+// NewPriceDroppedEvent creates an event for when a Dutch auction lowers its asking price
+func NewPriceDroppedEvent(auction *Auction) *AuctionEvent {
+	return &AuctionEvent{
+		Type:    EventPriceDropped,
+		Auction: auction,
+	}
+}
+
+// NewAuctionCancelledEvent creates an event for when an operator cancels an auction
+func NewAuctionCancelledEvent(auction *Auction) *AuctionEvent {
+	return &AuctionEvent{
+		Type:    EventAuctionCancelled,
+		Auction: auction,
+	}
+}
+
+// NewAuctionPausedEvent creates an event for when an operator pauses an auction
+func NewAuctionPausedEvent(auction *Auction) *AuctionEvent {
+	return &AuctionEvent{
+		Type:    EventAuctionPaused,
+		Auction: auction,
+	}
+}
+
+// NewAuctionResumedEvent creates an event for when an operator resumes a paused auction
+func NewAuctionResumedEvent(auction *Auction) *AuctionEvent {
+	return &AuctionEvent{
+		Type:    EventAuctionResumed,
+		Auction: auction,
+	}
+}
+
+// NewAuctionForceEndedEvent creates an event for when an operator ends an auction early
+func NewAuctionForceEndedEvent(auction *Auction) *AuctionEvent {
+	return &AuctionEvent{
+		Type:    EventAuctionForceEnded,
+		Auction: auction,
+	}
+}
+
+// NewResyncRequiredEvent creates an event carrying the current auction state for a
+// subscriber that cannot be resumed from its last seen sequence
+func NewResyncRequiredEvent(auction *Auction, sequence int) *AuctionEvent {
+	return &AuctionEvent{
+		Sequence: sequence,
+		Type:     EventResyncRequired,
+		Auction:  auction,
+	}
+}
+
 // NewErrorEvent creates an event for when an error occurs
 func NewErrorEvent(errMsg string) *AuctionEvent {
 	return &AuctionEvent{
@@ -49,7 +132,33 @@ func NewErrorEvent(errMsg string) *AuctionEvent {
 	}
 }
 
+// Public returns a copy of the event fit to send as plain JSON, with the
+// auction cut down by Auction.Public and the bidder's ID passed through redact
+func (e *AuctionEvent) Public(redact func(userID string) string) *AuctionEvent {
+	public := *e
+	if e.Auction != nil {
+		public.Auction = e.Auction.Public(redact)
+	}
+	if e.Bid != nil {
+		bid := *e.Bid
+		bid.UserID = redact(bid.UserID)
+		public.Bid = &bid
+	}
+	return &public
+}
+
 // IsError checks if this event represents an error
 func (e *AuctionEvent) IsError() bool {
 	return e.Error != nil
 }
+
+// AuctionID returns the ID of the auction this event belongs to, if any
+func (e *AuctionEvent) AuctionID() string {
+	if e.Auction != nil {
+		return e.Auction.ID
+	}
+	if e.Bid != nil {
+		return e.Bid.AuctionID
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// ExtensionMode decides how a late bid moves the end of an auction
+type ExtensionMode string
+
+const (
+	// ExtensionExtendBy adds Duration to the current end time
+	ExtensionExtendBy ExtensionMode = "EXTEND_BY"
+	// ExtensionResetTo sets the time remaining back to Duration. It never
+	// shortens the auction.
+	ExtensionResetTo ExtensionMode = "RESET_TO"
+)
+
+// ExtensionPolicy is an auction's anti-sniping rule: a bid placed with less
+// than Threshold seconds left extends the auction, up to the optional caps
+type ExtensionPolicy struct {
+	Mode              ExtensionMode `json:"mode"`
+	Threshold         int           `json:"threshold"`                   // seconds
+	Duration          int           `json:"duration"`                    // seconds
+	MaxExtensions     *int          `json:"maxExtensions,omitempty"`     // nil for no limit
+	MaxTotalExtension *int          `json:"maxTotalExtension,omitempty"` // seconds past the original end; nil for no limit
+}
+
+// DefaultExtensionPolicy resets the clock to 10 seconds whenever a bid comes
+// in during the last 10 seconds
+func DefaultExtensionPolicy() ExtensionPolicy {
+	return ExtensionPolicy{Mode: ExtensionResetTo, Threshold: 10, Duration: 10}
+}
+
+// ExtendedEndTime returns the end time a bid at now moves the auction to, or
+// false if the bid doesn't extend it. originalEnd and extensions say where the
+// auction would have ended and how often it was extended already, for the caps.
+func (p *ExtensionPolicy) ExtendedEndTime(endTime, originalEnd, now time.Time, extensions int) (time.Time, bool) {
+	remaining := endTime.Sub(now)
+	if remaining <= 0 || remaining >= seconds(p.Threshold) {
+		return time.Time{}, false
+	}
+	if p.MaxExtensions != nil && extensions >= *p.MaxExtensions {
+		return time.Time{}, false
+	}
+
+	extended := endTime.Add(seconds(p.Duration))
+	if p.Mode == ExtensionResetTo {
+		extended = now.Add(seconds(p.Duration))
+	}
+	if p.MaxTotalExtension != nil {
+		if limit := originalEnd.Add(seconds(*p.MaxTotalExtension)); extended.After(limit) {
+			extended = limit
+		}
+	}
+
+	if !extended.After(endTime) {
+		return time.Time{}, false
+	}
+	return extended, true
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
@@ -0,0 +1,62 @@
+package model
+
+import "math"
+
+// IncrementTier is one price band of an increment schedule: while the current
+// bid is below UpTo, a new bid has to raise it by at least Increment
+type IncrementTier struct {
+	UpTo      *Money `json:"upTo,omitempty"` // nil for the last, open-ended band
+	Increment Money  `json:"increment"`
+}
+
+// IncrementSchedule lists the price bands of an auction in ascending order
+type IncrementSchedule []IncrementTier
+
+// DefaultIncrementSchedule returns a flat increment of one currency unit
+func DefaultIncrementSchedule() IncrementSchedule {
+	return IncrementSchedule{{Increment: WholeUnits(1, "")}}
+}
+
+// IncrementAt returns the minimum raise over the given price. An empty
+// schedule, as on auctions saved before schedules existed, raises by one unit.
+func (s IncrementSchedule) IncrementAt(price Money) Money {
+	for _, tier := range s {
+		if tier.UpTo == nil || price.Cmp(*tier.UpTo) < 0 {
+			return tier.Increment
+		}
+	}
+	if len(s) > 0 {
+		return s[len(s)-1].Increment
+	}
+	return WholeUnits(1, price.Currency)
+}
+
+// Next returns the lowest bid that beats price. A price so high that nothing
+// can beat it gives the largest amount there is.
+func (s IncrementSchedule) Next(price Money) Money {
+	next, err := price.Add(s.IncrementAt(price))
+	if err != nil {
+		return Money{Cents: math.MaxInt64, Currency: price.Currency}
+	}
+	return next
+}
+
+// In returns a copy of the schedule with every amount tied to a currency
+func (s IncrementSchedule) In(currency string) (IncrementSchedule, error) {
+	converted := make(IncrementSchedule, len(s))
+	for i, tier := range s {
+		increment, err := tier.Increment.In(currency)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = IncrementTier{Increment: increment}
+		if tier.UpTo != nil {
+			upTo, err := tier.UpTo.In(currency)
+			if err != nil {
+				return nil, err
+			}
+			converted[i].UpTo = &upTo
+		}
+	}
+	return converted, nil
+}
@@ -0,0 +1,255 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for auctions that don't name a currency
+const DefaultCurrency = "USD"
+
+// Money is an exact amount in a currency. It is held in cents (hundredths of
+// the currency unit), so sums and comparisons never round.
+type Money struct {
+	Cents    int64
+	Currency string // ISO 4217 code; empty until it is tied to an auction
+}
+
+// NewMoney returns the given number of cents in a currency
+func NewMoney(cents int64, currency string) Money {
+	return Money{Cents: cents, Currency: currency}
+}
+
+// WholeUnits returns a whole number of currency units, such as 100 dollars
+func WholeUnits(units int64, currency string) Money {
+	return Money{Cents: units * 100, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount with at most two fractional digits and an
+// optional currency code, such as "150", "150.5" or "150.50 USD"
+func ParseMoney(s string) (Money, error) {
+	amount, currency, _ := strings.Cut(strings.TrimSpace(s), " ")
+	currency = strings.TrimSpace(currency)
+	if currency != "" {
+		if err := ValidateCurrency(currency); err != nil {
+			return Money{}, err
+		}
+	}
+
+	whole, frac, hasFrac := strings.Cut(amount, ".")
+	negative := strings.HasPrefix(whole, "-")
+	whole = strings.TrimPrefix(whole, "-")
+	if whole == "" || !digitsOnly(whole) || !digitsOnly(frac) || len(frac) > 2 || (hasFrac && frac == "") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > maxUnits {
+		return Money{}, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+
+	m := Money{Cents: units*100 + cents, Currency: currency}
+	if negative {
+		m.Cents = -m.Cents
+	}
+	return m, nil
+}
+
+// MustParseMoney is like ParseMoney but panics if s is not a valid amount
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// maxUnits keeps an amount's cents within an int64
+const maxUnits = 1<<63/100 - 1
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateCurrency checks that code looks like an ISO 4217 currency code
+func ValidateCurrency(code string) error {
+	if len(code) != 3 {
+		return ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return ErrInvalidCurrency
+		}
+	}
+	return nil
+}
+
+// String formats the amount with two decimals followed by its currency code
+func (m Money) String() string {
+	sign := ""
+	cents := m.Cents
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	s := fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+	if m.Currency != "" {
+		s += " " + m.Currency
+	}
+	return s
+}
+
+// In ties the amount to a currency. An amount that already names a different
+// currency can't be converted.
+func (m Money) In(currency string) (Money, error) {
+	if m.Currency != "" && m.Currency != currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	m.Currency = currency
+	return m, nil
+}
+
+// Add returns m + o in m's currency. A sum that doesn't fit in an int64 of
+// cents is an ErrInvalidMoney rather than wrapping around.
+func (m Money) Add(o Money) (Money, error) {
+	sum := m.Cents + o.Cents
+	if (o.Cents > 0 && sum < m.Cents) || (o.Cents < 0 && sum > m.Cents) {
+		return Money{}, fmt.Errorf("%w: %s + %s is out of range", ErrInvalidMoney, m, o)
+	}
+	return Money{Cents: sum, Currency: m.currencyWith(o)}, nil
+}
+
+// Sub returns m - o in m's currency
+func (m Money) Sub(o Money) Money {
+	return Money{Cents: m.Cents - o.Cents, Currency: m.currencyWith(o)}
+}
+
+// Times returns m multiplied by n, e.g. a per-unit price by a quantity. A
+// product that doesn't fit in an int64 of cents is an ErrInvalidMoney.
+func (m Money) Times(n int) (Money, error) {
+	product := m.Cents * int64(n)
+	if n != 0 && (product/int64(n) != m.Cents || (n == -1 && m.Cents == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s * %d is out of range", ErrInvalidMoney, m, n)
+	}
+	return Money{Cents: product, Currency: m.Currency}, nil
+}
+
+// currencyWith returns m's currency, or o's if m isn't tied to one yet
+func (m Money) currencyWith(o Money) string {
+	if m.Currency == "" {
+		return o.Currency
+	}
+	return m.Currency
+}
+
+// Cmp compares the amounts: -1 if m < o, 0 if they are equal, +1 if m > o.
+// Currencies are checked when amounts enter an auction, not here.
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.Cents < o.Cents:
+		return -1
+	case m.Cents > o.Cents:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsPositive returns true for amounts above zero
+func (m Money) IsPositive() bool {
+	return m.Cents > 0
+}
+
+// MaxMoney returns the larger of two amounts
+func MaxMoney(a, b Money) Money {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// MinMoney returns the smaller of two amounts
+func MinMoney(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// MarshalJSON encodes the amount as a string such as "150.50 USD"
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON decodes a string amount, or a plain number as written before
+// amounts were fixed-point. Such numbers are rounded to the nearest cent.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var f float64
+		if err := json.Unmarshal(data, &f); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidMoney, data)
+		}
+		*m = Money{Cents: int64(math.Round(f * 100))}
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MarshalGQL writes the amount as a GraphQL string such as "150.50 USD"
+func (m Money) MarshalGQL(w io.Writer) {
+	io.WriteString(w, strconv.Quote(m.String()))
+}
+
+// UnmarshalGQL accepts a string such as "150.50" or "150.50 USD", or a number
+func (m *Money) UnmarshalGQL(v any) error {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("%w: %T is not an amount", ErrInvalidMoney, v)
+	}
+	parsed, err := parseLooseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// parseLooseMoney parses an amount that may come from a float, such as
+// "150.1" or "1e2". Numbers with more than two decimals are still rejected.
+func parseLooseMoney(s string) (Money, error) {
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+		}
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return ParseMoney(s)
+}
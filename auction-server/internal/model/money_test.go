@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"150", NewMoney(15000, "")},
+		{"150.5", NewMoney(15050, "")},
+		{"150.05 USD", NewMoney(15005, "USD")},
+		{"0.01 EUR", NewMoney(1, "EUR")},
+		{"-2.50", NewMoney(-250, "")},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in)
+		if err != nil {
+			t.Errorf("ParseMoney(%q): unexpected error %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1.234", "1.", ".5", "1,00", "10 usd", "10 DOLLARS"} {
+		if _, err := ParseMoney(in); err == nil {
+			t.Errorf("ParseMoney(%q): expected an error", in)
+		}
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	if got := NewMoney(10010, "USD").String(); got != "100.10 USD" {
+		t.Errorf("expected 100.10 USD, got %s", got)
+	}
+	if got := NewMoney(-5, "").String(); got != "-0.05" {
+		t.Errorf("expected -0.05, got %s", got)
+	}
+}
+
+func TestMoney_ArithmeticIsExact(t *testing.T) {
+	// 100.1 + 1.0 isn't 101.1 in float64
+	sum, err := MustParseMoney("100.1 USD").Add(WholeUnits(1, ""))
+	if err != nil || sum != MustParseMoney("101.1 USD") {
+		t.Errorf("expected 101.10 USD, got %s (%v)", sum, err)
+	}
+}
+
+func TestMoney_ArithmeticRejectsOverflow(t *testing.T) {
+	huge := MustParseMoney("50000000000000000 USD")
+	if total, err := huge.Times(2); !errors.Is(err, ErrInvalidMoney) {
+		t.Errorf("expected ErrInvalidMoney for two units of %s, got %s (%v)", huge, total, err)
+	}
+	if sum, err := huge.Add(huge); !errors.Is(err, ErrInvalidMoney) {
+		t.Errorf("expected ErrInvalidMoney for %s twice over, got %s (%v)", huge, sum, err)
+	}
+	if sum, err := NewMoney(math.MinInt64+1, "").Add(NewMoney(-2, "")); !errors.Is(err, ErrInvalidMoney) {
+		t.Errorf("expected ErrInvalidMoney below the smallest amount, got %s (%v)", sum, err)
+	}
+	if total, err := huge.Times(-1); err != nil || total.Cents != -huge.Cents {
+		t.Errorf("expected -%s, got %s (%v)", huge, total, err)
+	}
+}
+
+func TestMoney_UnmarshalGQL(t *testing.T) {
+	var m Money
+	if err := m.UnmarshalGQL(100.1); err != nil || m != NewMoney(10010, "") {
+		t.Errorf("expected 100.10 from a float, got %s (%v)", m, err)
+	}
+	if err := m.UnmarshalGQL("42 GBP"); err != nil || m != NewMoney(4200, "GBP") {
+		t.Errorf("expected 42.00 GBP, got %s (%v)", m, err)
+	}
+	if err := m.UnmarshalGQL(0.125); !errors.Is(err, ErrInvalidMoney) {
+		t.Errorf("expected ErrInvalidMoney for a fraction of a cent, got %v", err)
+	}
+}
+
+func TestMoney_JSONAcceptsLegacyNumbers(t *testing.T) {
+	var bid Bid
+	if err := json.Unmarshal([]byte(`{"amount": 150.1}`), &bid); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if bid.Amount != NewMoney(15010, "") {
+		t.Errorf("expected 150.10, got %s", bid.Amount)
+	}
+
+	data, err := json.Marshal(NewMoney(15010, "USD"))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != `"150.10 USD"` {
+		t.Errorf("expected \"150.10 USD\", got %s", data)
+	}
+}
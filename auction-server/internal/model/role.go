@@ -0,0 +1,15 @@
+package model
+
+// Role is what a user may do, as granted by the "roles" claim of their token
+type Role string
+
+const (
+	// RoleAdmin runs the marketplace: operator controls and unredacted user IDs
+	RoleAdmin Role = "ADMIN"
+	// RoleSeller creates auctions
+	RoleSeller Role = "SELLER"
+	// RoleBidder places bids and buys now
+	RoleBidder Role = "BIDDER"
+	// RoleViewer only watches; it is what a token without roles gets
+	RoleViewer Role = "VIEWER"
+)
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// VerificationStatus tracks a bidder's identity check
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "PENDING"
+	VerificationVerified VerificationStatus = "VERIFIED"
+	VerificationRejected VerificationStatus = "REJECTED"
+)
+
+// User is a registered bidder. The ID is the subject of the user's token, so
+// it matches Bid.UserID.
+type User struct {
+	ID               string             `json:"id"`
+	DisplayName      string             `json:"displayName"`
+	Verification     VerificationStatus `json:"verification"`
+	Suspended        bool               `json:"suspended"`
+	SuspensionReason string             `json:"suspensionReason,omitempty"`
+	RegisteredAt     time.Time          `json:"registeredAt"`
+	VerifiedAt       *time.Time         `json:"verifiedAt,omitempty"`
+
+	// Credit is the deposit the user bids against, one balance per currency.
+	// Holds commit parts of it to auctions they may win; Settlements record
+	// what they were charged for the ones they won.
+	Credit      []Money      `json:"credit,omitempty"`
+	Holds       []Hold       `json:"holds,omitempty"`
+	Settlements []Settlement `json:"settlements,omitempty"`
+}
+
+// Eligibility returns why the user may not bid, or nil if they may
+func (u *User) Eligibility() error {
+	if u.Suspended {
+		return NewBidderSuspendedError(u.ID, u.SuspensionReason)
+	}
+	if u.Verification != VerificationVerified {
+		return &BidderError{Err: ErrBidderNotVerified, UserID: u.ID}
+	}
+	return nil
+}
+
+// CanBid reports whether the user is currently eligible to bid
+func (u *User) CanBid() bool {
+	return u.Eligibility() == nil
+}
@@ -1,42 +1,101 @@
 package model
 
-import "time"
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
 
 // ValidationRules contains configuration for auction validation
 type ValidationRules struct {
-	MinStartingBid     float64
-	MaxStartingBid     float64
-	MinDuration        int
-	MaxDuration        int
-	MinBidIncrement    float64
-	ExtensionThreshold time.Duration
-	ExtensionDuration  time.Duration
+	MinStartingBid    Money // thresholds carry no currency and apply to every auction
+	MaxStartingBid    Money
+	MaxBid            Money // also caps increments, so sums of bids stay far from overflowing
+	MaxQuantity       int   // units in a multi-unit lot
+	MinDuration       int
+	MaxDuration       int
+	Increments        IncrementSchedule // for auctions created without their own schedule
+	Extension         ExtensionPolicy   // for auctions with extended bidding that don't set their own policy
+	BuyNowCutoff      int64             // buy-now closes once a bid reaches this percentage of the buy-now price
+	MaxDisplayName    int               // characters
+	MaxIdempotencyKey int               // bytes
+	MinWebhookSecret  int               // bytes
 }
 
 // DefaultValidationRules returns the default validation rules
 func DefaultValidationRules() *ValidationRules {
 	return &ValidationRules{
-		MinStartingBid:     1.0,
-		MaxStartingBid:     1000000.0,
-		MinDuration:        10,
-		MaxDuration:        3600,
-		MinBidIncrement:    1.0,
-		ExtensionThreshold: 10 * time.Second,
-		ExtensionDuration:  10 * time.Second,
+		MinStartingBid:    WholeUnits(1, ""),
+		MaxStartingBid:    WholeUnits(1000000, ""),
+		MaxBid:            WholeUnits(100000000, ""),
+		MaxQuantity:       10000,
+		MinDuration:       10,
+		MaxDuration:       3600,
+		Increments:        DefaultIncrementSchedule(),
+		Extension:         DefaultExtensionPolicy(),
+		BuyNowCutoff:      50,
+		MaxDisplayName:    50,
+		MaxIdempotencyKey: 255,
+		MinWebhookSecret:  16,
 	}
 }
 
 // ValidateStartingBid checks if the starting bid is valid
-func (vr *ValidationRules) ValidateStartingBid(amount float64) error {
-	if amount < vr.MinStartingBid {
+func (vr *ValidationRules) ValidateStartingBid(amount Money) error {
+	if amount.Cmp(vr.MinStartingBid) < 0 {
 		return ErrInvalidStartingBid
 	}
-	if amount > vr.MaxStartingBid {
+	if amount.Cmp(vr.MaxStartingBid) > 0 {
 		return ErrInvalidStartingBid
 	}
 	return nil
 }
 
+// ValidateReservePrice checks that a reserve price isn't below the starting bid
+func (vr *ValidationRules) ValidateReservePrice(reservePrice, startingBid Money) error {
+	if reservePrice.Cmp(startingBid) < 0 {
+		return ErrInvalidReservePrice
+	}
+	if reservePrice.Cmp(vr.MaxStartingBid) > 0 {
+		return ErrInvalidReservePrice
+	}
+	return nil
+}
+
+// ValidateBuyNowPrice checks that buying now costs more than the starting bid
+// and is enough to meet the reserve
+func (vr *ValidationRules) ValidateBuyNowPrice(buyNowPrice, startingBid Money, reservePrice *Money) error {
+	if buyNowPrice.Cmp(startingBid) <= 0 || buyNowPrice.Cmp(vr.MaxStartingBid) > 0 {
+		return ErrInvalidBuyNowPrice
+	}
+	if reservePrice != nil && buyNowPrice.Cmp(*reservePrice) < 0 {
+		return ErrInvalidBuyNowPrice
+	}
+	return nil
+}
+
+// BuyNowAvailable checks if the auction can still be bought outright: it has a
+// buy-now price and no bid has reached the cutoff percentage of it yet
+func (vr *ValidationRules) BuyNowAvailable(auction *Auction) bool {
+	if auction.BuyNowPrice == nil || auction.Status != AuctionStatusActive {
+		return false
+	}
+	// Compared in hundredths of a cent so the percentage never rounds
+	return !auction.HasBids() || auction.CurrentBid.Cents*100 < auction.BuyNowPrice.Cents*vr.BuyNowCutoff
+}
+
+// ValidateDutchSchedule checks that a Dutch auction's price actually falls and
+// stays above zero
+func (vr *ValidationRules) ValidateDutchSchedule(dropAmount Money, dropInterval int, floorPrice, startingBid Money) error {
+	if !dropAmount.IsPositive() || dropInterval <= 0 {
+		return ErrInvalidPriceDrop
+	}
+	if !floorPrice.IsPositive() || floorPrice.Cmp(startingBid) >= 0 {
+		return ErrInvalidPriceDrop
+	}
+	return nil
+}
+
 // ValidateDuration checks if the auction duration is valid
 func (vr *ValidationRules) ValidateDuration(duration int) error {
 	if duration < vr.MinDuration {
@@ -48,37 +107,125 @@ func (vr *ValidationRules) ValidateDuration(duration int) error {
 	return nil
 }
 
-// ValidateBidAmount checks if a bid amount is valid
-func (vr *ValidationRules) ValidateBidAmount(amount, currentBid float64) error {
-	if amount <= 0 {
-		return ErrInvalidBidAmount
+// ValidateBidAmount checks if a bid amount is valid. The bid must be in the
+// same currency as the current bid and raise it by at least the increment the
+// schedule sets for that price; a nil schedule uses the default one.
+func (vr *ValidationRules) ValidateBidAmount(amount, currentBid Money, increments IncrementSchedule) error {
+	if amount.Currency != currentBid.Currency {
+		return ErrCurrencyMismatch
 	}
-	if amount <= currentBid {
+	if err := vr.ValidateBidLimit(amount); err != nil {
+		return err
+	}
+	if amount.Cmp(vr.CalculateNextMinimumBid(currentBid, increments)) < 0 {
 		return ErrBidTooLow
 	}
-	// Optionally enforce minimum increment
-	// if amount < currentBid + vr.MinBidIncrement {
-	//     return ErrBidTooLow
-	// }
+	return nil
+}
+
+// ValidateBidLimit checks that a bid, or a proxy maximum, is positive and no
+// more than MaxBid
+func (vr *ValidationRules) ValidateBidLimit(amount Money) error {
+	if !amount.IsPositive() || amount.Cmp(vr.MaxBid) > 0 {
+		return ErrInvalidBidAmount
+	}
+	return nil
+}
+
+// ValidateQuantity checks the number of units in a lot
+func (vr *ValidationRules) ValidateQuantity(quantity int) error {
+	if quantity < 1 || quantity > vr.MaxQuantity {
+		return ErrInvalidQuantity
+	}
 	return nil
 }
 
 // CalculateNextMinimumBid calculates the next valid minimum bid
-func (vr *ValidationRules) CalculateNextMinimumBid(currentBid float64) float64 {
-	return currentBid + vr.MinBidIncrement
+func (vr *ValidationRules) CalculateNextMinimumBid(currentBid Money, increments IncrementSchedule) Money {
+	if increments == nil {
+		increments = vr.Increments
+	}
+	return increments.Next(currentBid)
 }
 
-// ShouldExtendAuction determines if an auction should be extended
-func (vr *ValidationRules) ShouldExtendAuction(endTime time.Time, extendedBiddingEnabled bool) bool {
-	if !extendedBiddingEnabled {
-		return false
+// ValidateIncrements checks that a schedule's bands rise strictly, every
+// increment is positive, no amount exceeds MaxBid and only the last band is
+// open-ended
+func (vr *ValidationRules) ValidateIncrements(increments IncrementSchedule) error {
+	if len(increments) == 0 {
+		return ErrInvalidIncrements
+	}
+	var previous *Money
+	for i, tier := range increments {
+		if !tier.Increment.IsPositive() || tier.Increment.Cmp(vr.MaxBid) > 0 {
+			return ErrInvalidIncrements
+		}
+		last := i == len(increments)-1
+		if (tier.UpTo == nil) != last {
+			return ErrInvalidIncrements
+		}
+		if tier.UpTo != nil {
+			if !tier.UpTo.IsPositive() || tier.UpTo.Cmp(vr.MaxBid) > 0 || (previous != nil && tier.UpTo.Cmp(*previous) <= 0) {
+				return ErrInvalidIncrements
+			}
+			previous = tier.UpTo
+		}
+	}
+	return nil
+}
+
+// ValidateExtensionPolicy checks that a policy has a known mode, a positive
+// threshold and duration, and caps of at least one if it sets any
+func (vr *ValidationRules) ValidateExtensionPolicy(policy ExtensionPolicy) error {
+	if policy.Mode != ExtensionExtendBy && policy.Mode != ExtensionResetTo {
+		return ErrInvalidExtensionPolicy
+	}
+	if policy.Threshold <= 0 || policy.Duration <= 0 {
+		return ErrInvalidExtensionPolicy
+	}
+	if (policy.MaxExtensions != nil && *policy.MaxExtensions < 1) ||
+		(policy.MaxTotalExtension != nil && *policy.MaxTotalExtension < 1) {
+		return ErrInvalidExtensionPolicy
 	}
+	return nil
+}
 
-	timeRemaining := time.Until(endTime)
-	return timeRemaining > 0 && timeRemaining < vr.ExtensionThreshold
+// ValidateDisplayName checks that a bidder's display name isn't blank or too long
+func (vr *ValidationRules) ValidateDisplayName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > vr.MaxDisplayName {
+		return ErrInvalidDisplayName
+	}
+	return nil
 }
 
-// CalculateExtendedEndTime calculates the new end time after extension
-func (vr *ValidationRules) CalculateExtendedEndTime(currentTime time.Time) time.Time {
-	return currentTime.Add(vr.ExtensionDuration)
+// ValidateIdempotencyKey checks that a client-supplied idempotency key isn't
+// blank or overly long
+func (vr *ValidationRules) ValidateIdempotencyKey(key string) error {
+	if strings.TrimSpace(key) == "" || len(key) > vr.MaxIdempotencyKey {
+		return ErrInvalidIdempotencyKey
+	}
+	return nil
+}
+
+// ValidateWebhook checks that a webhook posts to an absolute http(s) URL, is
+// registered for at least one broadcast event type and has a secret long
+// enough to sign with
+func (vr *ValidationRules) ValidateWebhook(rawURL string, eventTypes []AuctionEventType, secret string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if len(eventTypes) == 0 {
+		return ErrInvalidWebhookEvents
+	}
+	for _, eventType := range eventTypes {
+		if !eventType.IsBroadcast() {
+			return ErrInvalidWebhookEvents
+		}
+	}
+	if len(secret) < vr.MinWebhookSecret {
+		return ErrInvalidWebhookSecret
+	}
+	return nil
 }
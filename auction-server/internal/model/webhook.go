@@ -0,0 +1,54 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook is a URL that receives auction events as signed HTTP POSTs
+type Webhook struct {
+	ID         string             `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []AuctionEventType `json:"eventTypes"`
+	Secret     string             `json:"secret"` // signs deliveries; never exposed through the API
+	CreatedBy  string             `json:"createdBy"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+// Wants reports whether the webhook is registered for events of the given type
+func (w *Webhook) Wants(eventType AuctionEventType) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+// DeliveryStatus tracks a webhook delivery through the queue
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is waiting for its next attempt
+	DeliveryPending DeliveryStatus = "PENDING"
+	// DeliveryDead ran out of attempts and sits in the dead-letter list until
+	// an operator retries it
+	DeliveryDead DeliveryStatus = "DEAD"
+)
+
+// WebhookDelivery is one event queued for one webhook. The payload is encoded
+// when the event happens, so a retry sends what the first attempt did.
+// Deliveries are removed once the receiver accepts them.
+type WebhookDelivery struct {
+	ID            string           `json:"id"`
+	WebhookID     string           `json:"webhookId"`
+	EventType     AuctionEventType `json:"eventType"`
+	AuctionID     string           `json:"auctionId"`
+	Sequence      int              `json:"sequence"`
+	Payload       []byte           `json:"payload"`
+	Status        DeliveryStatus   `json:"status"`
+	Attempts      int              `json:"attempts"`
+	NextAttemptAt time.Time        `json:"nextAttemptAt"`
+	LastError     string           `json:"lastError,omitempty"`
+	CreatedAt     time.Time        `json:"createdAt"`
+}
+
+// IsDue reports whether the delivery should be attempted at the given time
+func (d *WebhookDelivery) IsDue(now time.Time) bool {
+	return d.Status == DeliveryPending && !now.Before(d.NextAttemptAt)
+}
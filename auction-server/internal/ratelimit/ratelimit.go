@@ -0,0 +1,171 @@
+// Package ratelimit throttles callers with token buckets. Each key, such as a
+// user or a client IP, gets its own bucket that refills at a steady rate up
+// to a burst size.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+)
+
+// ErrInvalidRule is returned for a limit that can't be parsed
+var ErrInvalidRule = errors.New("invalid rate limit")
+
+// Rule is a token bucket's refill rate and capacity
+type Rule struct {
+	Rate  float64 // tokens added per second
+	Burst int     // most tokens the bucket holds; defaults to the rate per second, at least 1
+}
+
+// ParseRule parses a limit such as "5/s", "120/m" or "5/s:20", where the
+// number after the colon is the burst
+func ParseRule(s string) (Rule, error) {
+	rate, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	count, unit, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("%w %q: want e.g. 5/s or 120/m:20", ErrInvalidRule, s)
+	}
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 {
+		return Rule{}, fmt.Errorf("%w %q: bad count", ErrInvalidRule, s)
+	}
+
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Rule{}, fmt.Errorf("%w %q: unit must be s, m or h", ErrInvalidRule, s)
+	}
+
+	rule := Rule{Rate: n / per.Seconds()}
+	if hasBurst {
+		if rule.Burst, err = strconv.Atoi(burst); err != nil || rule.Burst < 1 {
+			return Rule{}, fmt.Errorf("%w %q: bad burst", ErrInvalidRule, s)
+		}
+	}
+	return rule.withDefaults(), nil
+}
+
+// ParseRules parses a comma-separated list of name=limit pairs, e.g.
+// "placeBid=5/s:10,buyNow=1/s"
+func ParseRules(s string) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, limit, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%w %q: want name=limit", ErrInvalidRule, pair)
+		}
+		rule, err := ParseRule(limit)
+		if err != nil {
+			return nil, err
+		}
+		rules[strings.TrimSpace(name)] = rule
+	}
+	return rules, nil
+}
+
+func (r Rule) withDefaults() Rule {
+	if r.Burst < 1 {
+		r.Burst = max(1, int(math.Ceil(r.Rate)))
+	}
+	return r
+}
+
+// Limiter keeps a token bucket per key, all following the same rule
+type Limiter struct {
+	rule    Rule
+	clock   clock.Clock
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweepAt int // bucket count at which full buckets are dropped
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// minSweep is the bucket count below which full buckets are never dropped
+const minSweep = 1024
+
+// New creates a limiter that tells time by the given clock
+func New(rule Rule, c clock.Clock) *Limiter {
+	return &Limiter{
+		rule:    rule.withDefaults(),
+		clock:   c,
+		buckets: make(map[string]*bucket),
+		sweepAt: minSweep,
+	}
+}
+
+// Allow takes a token from the key's bucket. If the bucket is empty it returns
+// false and how long until a token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		l.sweep(now)
+		b = &bucket{tokens: float64(l.rule.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rule.Rate * float64(time.Second))
+	return false, wait
+}
+
+// Refund puts back a token Allow took from the key's bucket, for a call that
+// was refused before it ran
+func (l *Limiter) Refund(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		l.refill(b, l.clock.Now())
+		b.tokens = math.Min(float64(l.rule.Burst), b.tokens+1)
+	}
+}
+
+// refill adds the tokens earned since the bucket was last used
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(l.rule.Burst), b.tokens+elapsed*l.rule.Rate)
+	}
+	b.updated = now
+}
+
+// sweep drops buckets that have refilled completely, since a new bucket starts
+// full anyway. It only runs once the map has doubled since the last sweep.
+func (l *Limiter) sweep(now time.Time) {
+	if len(l.buckets) < l.sweepAt {
+		return
+	}
+	for key, b := range l.buckets {
+		if l.refill(b, now); b.tokens >= float64(l.rule.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.sweepAt = max(minSweep, 2*len(l.buckets))
+}
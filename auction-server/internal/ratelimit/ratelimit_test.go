@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+)
+
+func TestParseRule(t *testing.T) {
+	cases := map[string]Rule{
+		"5/s":     {Rate: 5, Burst: 5},
+		"5/s:10":  {Rate: 5, Burst: 10},
+		"120/m":   {Rate: 2, Burst: 2},
+		"30/h:3":  {Rate: 30.0 / 3600, Burst: 3},
+		" 1/s:1 ": {Rate: 1, Burst: 1},
+	}
+	for in, want := range cases {
+		got, err := ParseRule(in)
+		if err != nil || got != want {
+			t.Errorf("%q: expected %+v, got %+v (%v)", in, want, got, err)
+		}
+	}
+
+	for _, in := range []string{"", "5", "0/s", "-1/s", "5/d", "5/s:0", "five/s"} {
+		if _, err := ParseRule(in); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%q: expected ErrInvalidRule, got %v", in, err)
+		}
+	}
+
+	rules, err := ParseRules("placeBid=5/s:10, *=20/s")
+	if err != nil || len(rules) != 2 || rules["placeBid"].Burst != 10 || rules["*"].Rate != 20 {
+		t.Errorf("expected placeBid and * rules, got %+v (%v)", rules, err)
+	}
+	if _, err := ParseRules("placeBid"); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule without a limit, got %v", err)
+	}
+}
+
+func TestLimiter_BurstThenRefill(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	limiter := New(Rule{Rate: 2, Burst: 3}, clk)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("alice"); !ok {
+			t.Fatalf("call %d: expected the burst to be allowed", i+1)
+		}
+	}
+	ok, retryAfter := limiter.Allow("alice")
+	if ok || retryAfter != 500*time.Millisecond {
+		t.Errorf("expected a refusal with a 500ms retry-after, got %v and %s", ok, retryAfter)
+	}
+	if ok, _ := limiter.Allow("bob"); !ok {
+		t.Errorf("expected bob to have a bucket of his own")
+	}
+
+	clk.Advance(500 * time.Millisecond)
+	if ok, _ := limiter.Allow("alice"); !ok {
+		t.Errorf("expected a token after the retry-after")
+	}
+	if ok, _ := limiter.Allow("alice"); ok {
+		t.Errorf("expected the refilled token to be spent")
+	}
+}
+
+func TestLimiter_SweepsFullBuckets(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	limiter := New(Rule{Rate: 1, Burst: 1}, clk)
+
+	for i := 0; i < minSweep; i++ {
+		limiter.Allow(string(rune('a' + i)))
+	}
+	clk.Advance(time.Second)
+	limiter.Allow("newcomer")
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("expected refilled buckets to be dropped, got %d", len(limiter.buckets))
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	var got string
+	handler := func(trustedProxies int) http.Handler {
+		return ClientIP(trustedProxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = ClientIPFromContext(r.Context())
+		}))
+	}
+
+	// The client sent a made-up entry; two proxies then added 203.0.113.9 and 10.0.0.1
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = "10.0.0.7:54321"
+	req.Header.Add("X-Forwarded-For", "198.51.100.1, 203.0.113.9")
+	req.Header.Add("X-Forwarded-For", "10.0.0.1")
+
+	cases := map[int]string{
+		0: "10.0.0.7",
+		1: "10.0.0.1",
+		2: "203.0.113.9",
+		5: "198.51.100.1", // fewer entries than proxies: the left-most is all there is
+	}
+	for trustedProxies, want := range cases {
+		handler(trustedProxies).ServeHTTP(httptest.NewRecorder(), req)
+		if got != want {
+			t.Errorf("%d trusted proxies: expected %q, got %q", trustedProxies, want, got)
+		}
+	}
+}
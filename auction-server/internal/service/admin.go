@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// CancelAuction calls off a pending, active or paused auction. All of its bids
+// are voided and nobody wins. Like the other operator actions, a non-empty
+// sellerID limits it to that seller's auctions; admins pass "".
+func (s *AuctionService) CancelAuction(ctx context.Context, auctionID string, sellerID string) (*model.Auction, error) {
+	return s.changeStatus(auctionID, sellerID,
+		[]model.AuctionStatus{model.AuctionStatusPending, model.AuctionStatusActive, model.AuctionStatusPaused},
+		eventlog.NewAuctionCancelledRecord,
+		func(a *model.Auction, now time.Time) { a.Cancel() },
+		model.NewAuctionCancelledEvent)
+}
+
+// PauseAuction freezes an active auction. Bids are rejected and the countdown
+// stops until the auction is resumed with the time it had left.
+func (s *AuctionService) PauseAuction(ctx context.Context, auctionID string, sellerID string) (*model.Auction, error) {
+	return s.changeStatus(auctionID, sellerID,
+		[]model.AuctionStatus{model.AuctionStatusActive},
+		eventlog.NewAuctionPausedRecord,
+		(*model.Auction).Pause,
+		model.NewAuctionPausedEvent)
+}
+
+// ResumeAuction reopens a paused auction with the time it had left when paused
+func (s *AuctionService) ResumeAuction(ctx context.Context, auctionID string, sellerID string) (*model.Auction, error) {
+	return s.changeStatus(auctionID, sellerID,
+		[]model.AuctionStatus{model.AuctionStatusPaused},
+		eventlog.NewAuctionResumedRecord,
+		(*model.Auction).Resume,
+		model.NewAuctionResumedEvent)
+}
+
+// ForceEndAuction closes an active or paused auction right away. The winner is
+// decided exactly as if its time had run out.
+func (s *AuctionService) ForceEndAuction(ctx context.Context, auctionID string, sellerID string) (*model.Auction, error) {
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	auction, err := s.managedAuction(auctionID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if auction.Status != model.AuctionStatusActive && auction.Status != model.AuctionStatusPaused {
+		return nil, model.ErrInvalidStatusChange
+	}
+
+	if err := s.closeAuction(auction, s.clock.Now(), model.NewAuctionForceEndedEvent); err != nil {
+		return nil, err
+	}
+	return auction, nil
+}
+
+// changeStatus applies an operator action to an auction in one of the allowed
+// statuses: it records the action, applies it and broadcasts the event. A
+// cancelled auction releases every hold on it.
+func (s *AuctionService) changeStatus(
+	auctionID string,
+	sellerID string,
+	allowed []model.AuctionStatus,
+	newRecord func(auctionID string, at time.Time) eventlog.Record,
+	apply func(a *model.Auction, now time.Time),
+	newEvent func(*model.Auction) *model.AuctionEvent,
+) (*model.Auction, error) {
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	auction, err := s.managedAuction(auctionID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowed, auction.Status) {
+		return nil, model.ErrInvalidStatusChange
+	}
+
+	now := s.clock.Now()
+	if err := s.commit(newRecord(auction.ID, now), func() error {
+		return s.store.UpdateAuction(auction.ID, func(a *model.Auction) error {
+			apply(a, now)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	if auction.Status == model.AuctionStatusCancelled {
+		s.settle(auction, now)
+	}
+
+	s.store.Broadcast(newEvent(auction))
+	return auction, nil
+}
+
+// managedAuction returns the auction an operator action applies to, provided
+// sellerID is empty or the seller who created it
+func (s *AuctionService) managedAuction(auctionID string, sellerID string) (*model.Auction, error) {
+	auction := s.store.GetAuction(auctionID)
+	if auction == nil {
+		return nil, model.ErrAuctionNotFound
+	}
+	if sellerID != "" && auction.SellerID != sellerID {
+		return nil, model.ErrNotAuctionSeller
+	}
+	return auction, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestPauseAuction_PreservesRemainingTime(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	clk.Advance(10 * time.Second)
+	if _, err := svc.PauseAuction(context.Background(), auction.ID, ""); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+
+	// The countdown stands still while paused
+	clk.Advance(time.Minute)
+	if remaining := svc.GetTimeRemaining(auction.ID); remaining != 20 {
+		t.Errorf("expected 20 seconds left, got %d", remaining)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); !errors.Is(err, model.ErrAuctionPaused) {
+		t.Errorf("expected ErrAuctionPaused, got %v", err)
+	}
+
+	if _, err := svc.ResumeAuction(context.Background(), auction.ID, ""); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if auction.Status != model.AuctionStatusActive {
+		t.Errorf("expected status ACTIVE, got %s", auction.Status)
+	}
+	if remaining := svc.GetTimeRemaining(auction.ID); remaining != 20 {
+		t.Errorf("expected 20 seconds left after resuming, got %d", remaining)
+	}
+	if _, err := svc.ResumeAuction(context.Background(), auction.ID, ""); !errors.Is(err, model.ErrInvalidStatusChange) {
+		t.Errorf("expected ErrInvalidStatusChange resuming an active auction, got %v", err)
+	}
+}
+
+func TestCancelAuction_VoidsBids(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	events := svc.Subscribe("test", auction.ID)
+
+	if _, err := svc.CancelAuction(context.Background(), auction.ID, ""); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+
+	if auction.Status != model.AuctionStatusCancelled {
+		t.Errorf("expected status CANCELLED, got %s", auction.Status)
+	}
+	if auction.CurrentWinner != nil || auction.CurrentBid != usd(100) {
+		t.Errorf("expected bids to be void, got %v at %s", auction.CurrentWinner, auction.CurrentBid)
+	}
+	if event := <-events; event.Type != model.EventAuctionCancelled {
+		t.Errorf("expected AUCTION_CANCELLED, got %s", event.Type)
+	}
+}
+
+func TestForceEndAuction_DeclaresWinner(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if _, err := svc.PauseAuction(context.Background(), auction.ID, ""); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	events := svc.Subscribe("test", auction.ID)
+
+	if _, err := svc.ForceEndAuction(context.Background(), auction.ID, ""); err != nil {
+		t.Fatalf("force end failed: %v", err)
+	}
+
+	if auction.Status != model.AuctionStatusEnded || auction.PausedAt != nil {
+		t.Errorf("expected an ended, unpaused auction, got %s", auction.Status)
+	}
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "alice" {
+		t.Errorf("expected alice to win, got %v", auction.CurrentWinner)
+	}
+	if event := <-events; event.Type != model.EventAuctionForceEnded {
+		t.Errorf("expected AUCTION_FORCE_ENDED, got %s", event.Type)
+	}
+}
+
+func TestOperatorActions_LimitedToSeller(t *testing.T) {
+	svc := NewAuctionService(store.NewAuctionStore())
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, SellerID: "sam"})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.ForceEndAuction(context.Background(), auction.ID, "sue"); !errors.Is(err, model.ErrNotAuctionSeller) {
+		t.Errorf("expected ErrNotAuctionSeller, got %v", err)
+	}
+	if _, err := svc.CancelAuction(context.Background(), auction.ID, "sue"); !errors.Is(err, model.ErrNotAuctionSeller) {
+		t.Errorf("expected ErrNotAuctionSeller, got %v", err)
+	}
+	if auction.Status != model.AuctionStatusActive {
+		t.Fatalf("expected the auction to stay ACTIVE, got %s", auction.Status)
+	}
+
+	if _, err := svc.CancelAuction(context.Background(), auction.ID, "sam"); err != nil {
+		t.Errorf("expected sam to cancel their own auction, got %v", err)
+	}
+}
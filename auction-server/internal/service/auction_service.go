@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
 	"sync"
 	"time"
 
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
 	"github.com/micahli/fl-auction/auction-server/internal/model"
 	"github.com/micahli/fl-auction/auction-server/internal/store"
 )
@@ -14,34 +17,139 @@ import (
 type AuctionService struct {
 	store          *store.AuctionStore
 	validationRule *model.ValidationRules
+	eventLog       *eventlog.Log
+	clock          clock.Clock
+	bidders        BidderRegistry
+	credit         CreditLedger
+	idempotency    idempotencyCache
 	timerMutex     sync.Mutex
 }
 
+// BidderRegistry decides whether a user may bid
+type BidderRegistry interface {
+	// CheckEligible returns why the user may not bid, or nil if they may
+	CheckEligible(userID string) error
+}
+
+// Option configures optional AuctionService dependencies
+type Option func(*AuctionService)
+
+// WithEventLog records every auction state change in the given log before it is applied
+func WithEventLog(l *eventlog.Log) Option {
+	return func(s *AuctionService) {
+		s.eventLog = l
+	}
+}
+
+// WithClock makes the service tell time by the given clock instead of the system clock
+func WithClock(c clock.Clock) Option {
+	return func(s *AuctionService) {
+		s.clock = c
+	}
+}
+
+// WithBidderRegistry refuses bids, maximums and buy-now from users the registry
+// doesn't find eligible
+func WithBidderRegistry(r BidderRegistry) Option {
+	return func(s *AuctionService) {
+		s.bidders = r
+	}
+}
+
+// WithBuyNowCutoff disables buy-now once a bid reaches the given percentage of
+// the buy-now price
+func WithBuyNowCutoff(percent int64) Option {
+	return func(s *AuctionService) {
+		s.validationRule.BuyNowCutoff = percent
+	}
+}
+
 // NewAuctionService creates a new auction service
-func NewAuctionService(store *store.AuctionStore) *AuctionService {
-	return &AuctionService{
+func NewAuctionService(store *store.AuctionStore, opts ...Option) *AuctionService {
+	s := &AuctionService{
 		store:          store,
 		validationRule: model.DefaultValidationRules(),
+		clock:          clock.Real,
+		idempotency:    newIdempotencyCache(DefaultIdempotencyWindow),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// CreateAuction creates and starts a new auction
-func (s *AuctionService) CreateAuction(ctx context.Context, startingBid float64, duration int, extendedBidding bool) (*model.Auction, error) {
+// CreateAuctionParams holds the settings for a new auction
+type CreateAuctionParams struct {
+	StartingBid     model.Money
+	Duration        int // seconds; defaults to 30
+	ExtendedBidding bool
+	ReservePrice    *model.Money // hidden minimum for a sale; nil for none
+
+	// ExtensionPolicy decides how late bids extend the auction. Setting it
+	// turns on ExtendedBidding; with ExtendedBidding alone the service's
+	// default policy applies.
+	ExtensionPolicy *model.ExtensionPolicy
+
+	// Currency is the ISO 4217 code every amount of the auction is in. It
+	// defaults to the starting bid's currency, then to USD. Amounts that name
+	// another currency are rejected.
+	Currency string
+
+	// Type defaults to ENGLISH. Dutch auctions start at StartingBid and drop
+	// by PriceDropAmount every PriceDropInterval seconds down to FloorPrice.
+	Type              model.AuctionType
+	PriceDropAmount   *model.Money
+	PriceDropInterval *int
+	FloorPrice        *model.Money // defaults to the minimum starting bid
+
+	// Increments sets the minimum raise per price band of an English auction;
+	// nil uses the service's default schedule
+	Increments model.IncrementSchedule
+
+	// ReplaceableBids lets each bidder in a sealed auction replace their bid
+	// until close instead of bidding only once
+	ReplaceableBids bool
+
+	// Quantity is the number of units in the lot; defaults to 1. Multi-unit
+	// lots are priced by Pricing, which defaults to UNIFORM.
+	Quantity int
+	Pricing  model.PricingRule
+
+	// BuyNowPrice lets a bidder end the auction at once by paying it; nil for none
+	BuyNowPrice *model.Money
+
+	// StartTime schedules the auction to open later; it stays PENDING until
+	// then. nil or a time that has already passed starts it immediately.
+	StartTime *time.Time
+
+	// SellerID is the user creating the auction. Besides admins, only they may
+	// cancel, pause, resume or end it.
+	SellerID string
+}
+
+// CreateAuction creates and starts a new auction alongside any already running
+func (s *AuctionService) CreateAuction(ctx context.Context, params CreateAuctionParams) (*model.Auction, error) {
 	s.timerMutex.Lock()
 	defer s.timerMutex.Unlock()
 
-	// Validate there's no active auction
-	current := s.store.GetCurrentAuction()
-	if current != nil && current.Status == model.AuctionStatusActive {
-		return nil, model.ErrAuctionAlreadyActive
+	// Tie every amount to the auction's currency
+	params, err := withCurrency(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.ExtensionPolicy != nil {
+		params.ExtendedBidding = true
 	}
 
 	// Validate starting bid
+	startingBid := params.StartingBid
 	if err := s.validationRule.ValidateStartingBid(startingBid); err != nil {
 		return nil, err
 	}
 
 	// Validate duration
+	duration := params.Duration
 	if duration <= 0 {
 		duration = 30 // Default duration
 	}
@@ -49,141 +157,557 @@ func (s *AuctionService) CreateAuction(ctx context.Context, startingBid float64,
 		return nil, err
 	}
 
+	// Validate reserve price
+	if params.ReservePrice != nil {
+		if err := s.validationRule.ValidateReservePrice(*params.ReservePrice, startingBid); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate buy-now price
+	if params.BuyNowPrice != nil {
+		if err := s.validationRule.ValidateBuyNowPrice(*params.BuyNowPrice, startingBid, params.ReservePrice); err != nil {
+			return nil, err
+		}
+	}
+
+	auctionType := params.Type
+	if auctionType == "" {
+		auctionType = model.AuctionTypeEnglish
+	}
+	// Scheduled auctions wait in PENDING until their start time
+	start, status := s.clock.Now(), model.AuctionStatusActive
+	if params.StartTime != nil && params.StartTime.After(start) {
+		start, status = *params.StartTime, model.AuctionStatusPending
+	}
+
+	var schedule *model.DutchSchedule
+	if params.ReplaceableBids && auctionType != model.AuctionTypeSealedFirstPrice && auctionType != model.AuctionTypeVickrey {
+		return nil, model.ErrUnsupportedForType
+	}
+	quantity := params.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	pricing, err := s.lotPricing(quantity, params.Pricing)
+	if err != nil {
+		return nil, err
+	}
+	if (quantity > 1 || params.BuyNowPrice != nil) && auctionType != model.AuctionTypeEnglish {
+		return nil, model.ErrUnsupportedForType
+	}
+	if quantity > 1 && params.BuyNowPrice != nil {
+		return nil, model.ErrUnsupportedForType
+	}
+
+	if params.Increments != nil && auctionType != model.AuctionTypeEnglish {
+		return nil, model.ErrUnsupportedForType
+	}
+
+	var policy *model.ExtensionPolicy
+	if params.ExtendedBidding {
+		if policy, err = s.extensionPolicy(params); err != nil {
+			return nil, err
+		}
+	}
+
+	var increments model.IncrementSchedule
+	switch auctionType {
+	case model.AuctionTypeEnglish:
+		if increments, err = s.incrementSchedule(params); err != nil {
+			return nil, err
+		}
+	case model.AuctionTypeSealedFirstPrice, model.AuctionTypeVickrey:
+		// Nobody can see the bids, so there is nothing to snipe
+		if params.ExtendedBidding {
+			return nil, model.ErrUnsupportedForType
+		}
+	case model.AuctionTypeDutch:
+		if schedule, err = s.newDutchSchedule(params, start); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, model.ErrInvalidAuctionType
+	}
+
 	// Create auction
-	now := time.Now()
 	auction := &model.Auction{
-		ID:              fmt.Sprintf("auction-%d", now.UnixNano()),
+		ID:              fmt.Sprintf("auction-%d", s.store.GetNextAuctionID()),
+		Type:            auctionType,
+		SellerID:        params.SellerID,
 		StartingBid:     startingBid,
 		CurrentBid:      startingBid,
 		CurrentWinner:   nil,
 		Duration:        duration,
-		ExtendedBidding: extendedBidding,
-		StartTime:       now,
-		EndTime:         now.Add(time.Duration(duration) * time.Second),
-		Status:          model.AuctionStatusActive,
+		ExtendedBidding: params.ExtendedBidding,
+		ExtensionPolicy: policy,
+		Increments:      increments,
+		ReservePrice:    params.ReservePrice,
+		DutchSchedule:   schedule,
+		ReplaceableBids: params.ReplaceableBids,
+		Quantity:        quantity,
+		Pricing:         pricing,
+		BuyNowPrice:     params.BuyNowPrice,
+		StartTime:       start,
+		EndTime:         start.Add(time.Duration(duration) * time.Second),
+		OriginalEndTime: start.Add(time.Duration(duration) * time.Second),
+		Status:          status,
 		Bids:            []model.Bid{},
 	}
 
-	s.store.SetCurrentAuction(auction)
+	if err := s.commit(eventlog.NewAuctionCreatedRecord(auction), func() error {
+		return s.store.SetAuction(auction)
+	}); err != nil {
+		return nil, err
+	}
+
+	if status == model.AuctionStatusPending {
+		go s.scheduleStart(auction.ID)
+		return auction, nil
+	}
 
 	// Broadcast auction started event
 	s.store.Broadcast(model.NewAuctionStartedEvent(auction))
 
-	// Start countdown timer
-	go s.startCountdown(auction)
+	// Each auction runs its own countdown timer
+	go s.startCountdown(auction.ID)
 
 	return auction, nil
 }
 
-// PlaceBid attempts to place a bid on the current auction
-func (s *AuctionService) PlaceBid(ctx context.Context, userID string, amount float64) (*model.Bid, error) {
+// withCurrency returns the params with every amount in the auction's currency
+func withCurrency(params CreateAuctionParams) (CreateAuctionParams, error) {
+	currency := params.Currency
+	if currency == "" {
+		currency = params.StartingBid.Currency
+	}
+	if currency == "" {
+		currency = model.DefaultCurrency
+	}
+	if err := model.ValidateCurrency(currency); err != nil {
+		return params, err
+	}
+	params.Currency = currency
+
+	var err error
+	if params.StartingBid, err = params.StartingBid.In(currency); err != nil {
+		return params, err
+	}
+	for _, amount := range []**model.Money{&params.ReservePrice, &params.PriceDropAmount, &params.FloorPrice, &params.BuyNowPrice} {
+		if *amount == nil {
+			continue
+		}
+		converted, err := (*amount).In(currency)
+		if err != nil {
+			return params, err
+		}
+		*amount = &converted
+	}
+	if params.Increments != nil {
+		if params.Increments, err = params.Increments.In(currency); err != nil {
+			return params, err
+		}
+	}
+	return params, nil
+}
+
+// extensionPolicy validates the extension policy of a new auction, or returns
+// a copy of the default one
+func (s *AuctionService) extensionPolicy(params CreateAuctionParams) (*model.ExtensionPolicy, error) {
+	policy := s.validationRule.Extension
+	if params.ExtensionPolicy != nil {
+		policy = *params.ExtensionPolicy
+	}
+	if err := s.validationRule.ValidateExtensionPolicy(policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// incrementSchedule validates the increment schedule of a new auction, or
+// returns the default one in the auction's currency
+func (s *AuctionService) incrementSchedule(params CreateAuctionParams) (model.IncrementSchedule, error) {
+	if params.Increments == nil {
+		return s.validationRule.Increments.In(params.Currency)
+	}
+	if err := s.validationRule.ValidateIncrements(params.Increments); err != nil {
+		return nil, err
+	}
+	return params.Increments, nil
+}
+
+// lotPricing validates the size and pricing rule of a lot
+func (s *AuctionService) lotPricing(quantity int, pricing model.PricingRule) (model.PricingRule, error) {
+	if err := s.validationRule.ValidateQuantity(quantity); err != nil {
+		return "", err
+	}
+	switch {
+	case quantity == 1 && pricing != "":
+		return "", model.ErrUnsupportedForType
+	case quantity == 1:
+		return "", nil
+	case pricing == "":
+		return model.PricingUniform, nil
+	case pricing == model.PricingUniform || pricing == model.PricingPayAsBid:
+		return pricing, nil
+	default:
+		return "", model.ErrInvalidPricingRule
+	}
+}
+
+// PlaceBid attempts to place a bid on the given auction. Any proxy bids that
+// can outbid it respond immediately.
+func (s *AuctionService) PlaceBid(ctx context.Context, auctionID string, userID string, amount model.Money) (*model.Bid, error) {
+	return s.PlaceBidForQuantity(ctx, auctionID, userID, amount, 1)
+}
+
+// PlaceBidForQuantity places a bid for quantity units of a multi-unit lot at
+// amount per unit. A bidder's latest bid replaces their earlier ones. For
+// single-unit auctions the quantity must be 1.
+func (s *AuctionService) PlaceBidForQuantity(ctx context.Context, auctionID string, userID string, amount model.Money, quantity int) (*model.Bid, error) {
+	if err := s.checkBidder(userID); err != nil {
+		return nil, err
+	}
+
 	s.timerMutex.Lock()
 	defer s.timerMutex.Unlock()
 
-	auction := s.store.GetCurrentAuction()
-	if auction == nil || auction.Status != model.AuctionStatusActive {
-		return nil, model.ErrNoActiveAuction
+	return s.placeBid(auctionID, userID, amount, quantity, s.clock.Now())
+}
+
+// placeBid validates and accepts a bid from an eligible bidder, then lets
+// proxies respond. Callers must hold timerMutex.
+func (s *AuctionService) placeBid(auctionID string, userID string, amount model.Money, quantity int, now time.Time) (*model.Bid, error) {
+	auction, err := s.biddableAuction(auctionID, now)
+	if err != nil {
+		return nil, err
+	}
+	if amount, err = amount.In(auction.Currency()); err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
+	if quantity < 1 || quantity > max(1, auction.Quantity) {
+		return nil, model.ErrInvalidQuantity
+	}
 
-	// Check if bid is too late
-	if now.After(auction.EndTime) {
-		return nil, model.NewBidTooLateError()
+	if auction.Type == model.AuctionTypeDutch {
+		return s.acceptDutchBid(auction, userID, amount, now)
+	}
+	if auction.IsSealed() {
+		return s.acceptSealedBid(auction, userID, amount, now)
 	}
 
 	// Validate bid amount
-	if err := s.validationRule.ValidateBidAmount(amount, auction.CurrentBid); err != nil {
+	if err := s.validationRule.ValidateBidAmount(amount, auction.CurrentBid, auction.Increments); err != nil {
 		if err == model.ErrBidTooLow {
-			return nil, model.NewBidTooLowError(auction.CurrentBid, amount)
+			bidErr := model.NewBidTooLowError(auction.CurrentBid, amount)
+			bidErr.TimeRemaining = auction.TimeRemaining(now)
+			return nil, bidErr
 		}
 		return nil, err
 	}
 
+	bid, err := s.acceptBid(auction, userID, amount, quantity, false, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resolveProxyBids(auction, now); err != nil {
+		return nil, err
+	}
+
+	return bid, nil
+}
+
+// checkBidder returns why the user may not bid, if a bidder registry is configured
+func (s *AuctionService) checkBidder(userID string) error {
+	if s.bidders == nil {
+		return nil
+	}
+	return s.bidders.CheckEligible(userID)
+}
+
+// biddableAuction returns the auction if it exists and is still accepting bids
+func (s *AuctionService) biddableAuction(auctionID string, now time.Time) (*model.Auction, error) {
+	auction := s.store.GetAuction(auctionID)
+	if auction == nil {
+		return nil, model.ErrAuctionNotFound
+	}
+	if auction.Status == model.AuctionStatusPending {
+		return nil, model.ErrAuctionNotStarted
+	}
+	if auction.Status == model.AuctionStatusPaused {
+		return nil, model.ErrAuctionPaused
+	}
+	if auction.Status != model.AuctionStatusActive {
+		return nil, model.ErrNoActiveAuction
+	}
+
+	// Check if bid is too late
+	if now.After(auction.EndTime) {
+		return nil, model.NewBidTooLateError()
+	}
+
+	return auction, nil
+}
+
+// acceptBid records an already validated bid as the new leading bid, extends the
+// auction if needed and broadcasts the bid. The bidder's credit is held for it
+// and the bidders it outbids get theirs back. Callers must hold timerMutex.
+func (s *AuctionService) acceptBid(auction *model.Auction, userID string, amount model.Money, quantity int, automatic bool, now time.Time) (*model.Bid, error) {
+	hold, err := requiredHold(auction, userID, amount, quantity)
+	if err != nil {
+		return nil, err
+	}
+	undo, err := s.holdFunds(auction, userID, hold)
+	if err != nil {
+		return nil, err
+	}
+	previousLeader := ""
+	if auction.CurrentWinner != nil {
+		previousLeader = *auction.CurrentWinner
+	}
+
 	// Create bid
 	bid := &model.Bid{
 		ID:        fmt.Sprintf("bid-%d", s.store.GetNextBidID()),
 		AuctionID: auction.ID,
 		UserID:    userID,
 		Amount:    amount,
+		Quantity:  quantity,
+		Automatic: automatic,
 		Timestamp: now,
 	}
 
 	// Add bid to auction
-	if err := s.store.AddBid(bid); err != nil {
+	if err := s.commit(eventlog.NewBidAcceptedRecord(bid), func() error {
+		return s.store.AddBid(bid)
+	}); err != nil {
+		undo()
 		return nil, err
 	}
+	s.releaseOutbid(auction, previousLeader)
 
 	// Handle extended bidding
-	if s.validationRule.ShouldExtendAuction(auction.EndTime, auction.ExtendedBidding) {
-		auction.EndTime = s.validationRule.CalculateExtendedEndTime(now)
+	endTime, extend := auction.ExtendedEndTime(now)
+	if extend {
+		if err := s.commit(eventlog.NewAuctionExtendedRecord(auction.ID, now, endTime), func() error {
+			return s.store.UpdateAuction(auction.ID, func(a *model.Auction) error {
+				a.Extend(endTime)
+				return nil
+			})
+		}); err != nil {
+			return nil, err
+		}
 	}
 
-	// Broadcast bid placed event
+	// Broadcast bid placed event, then the extension it caused
 	s.store.Broadcast(model.NewBidPlacedEvent(auction, bid))
+	if extend {
+		s.store.Broadcast(model.NewAuctionExtendedEvent(auction))
+	}
 
 	return bid, nil
 }
 
-// GetCurrentAuction returns the current auction
+// GetAuction returns the auction with the given ID, or nil if it doesn't exist
+func (s *AuctionService) GetAuction(id string) *model.Auction {
+	return s.store.GetAuction(id)
+}
+
+// GetCurrentAuction returns the most recently created auction
 func (s *AuctionService) GetCurrentAuction() *model.Auction {
 	return s.store.GetCurrentAuction()
 }
 
-// GetNextBid returns the minimum next valid bid
-func (s *AuctionService) GetNextBid() float64 {
-	auction := s.store.GetCurrentAuction()
+// ListAuctions returns all auctions, optionally filtered by status
+func (s *AuctionService) ListAuctions(status *model.AuctionStatus) []*model.Auction {
+	return s.store.ListAuctions(status)
+}
+
+// GetNextBid returns the minimum next valid bid for the given auction
+func (s *AuctionService) GetNextBid(auctionID string) model.Money {
+	auction := s.store.GetAuction(auctionID)
 	if auction == nil {
-		return 0
+		return model.Money{}
+	}
+	if auction.Type == model.AuctionTypeDutch || auction.IsSealed() {
+		return auction.NextBid()
 	}
-	return s.validationRule.CalculateNextMinimumBid(auction.CurrentBid)
+	return s.validationRule.CalculateNextMinimumBid(auction.CurrentBid, auction.Increments)
 }
 
-// GetTimeRemaining returns seconds remaining in the auction
-func (s *AuctionService) GetTimeRemaining() int {
-	auction := s.store.GetCurrentAuction()
-	if auction == nil || auction.Status != model.AuctionStatusActive {
+// GetTimeRemaining returns seconds remaining in the given auction
+func (s *AuctionService) GetTimeRemaining(auctionID string) int {
+	auction := s.store.GetAuction(auctionID)
+	if auction == nil {
 		return 0
 	}
 
-	return auction.TimeRemaining()
+	return auction.TimeRemaining(s.clock.Now())
 }
 
-// startCountdown runs a countdown timer for the auction
-func (s *AuctionService) startCountdown(auction *model.Auction) {
-	ticker := time.NewTicker(1 * time.Second)
+// ResumeCountdowns restarts the countdown for every active or paused auction in
+// the store and reschedules every pending one. Call it once on startup when the store was
+// restored from persistent storage; auctions whose end time passed while the
+// server was down end on the first tick.
+func (s *AuctionService) ResumeCountdowns() int {
+	active, paused := model.AuctionStatusActive, model.AuctionStatusPaused
+	auctions := append(s.store.ListAuctions(&active), s.store.ListAuctions(&paused)...)
+	for _, auction := range auctions {
+		go s.startCountdown(auction.ID)
+	}
+
+	pending := model.AuctionStatusPending
+	scheduled := s.store.ListAuctions(&pending)
+	for _, auction := range scheduled {
+		go s.scheduleStart(auction.ID)
+	}
+	return len(auctions) + len(scheduled)
+}
+
+// startCountdown runs a countdown timer for a single auction
+func (s *AuctionService) startCountdown(auctionID string) {
+	ticker := s.clock.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	for {
-		<-ticker.C
+		<-ticker.C()
 
-		current := s.store.GetCurrentAuction()
-		if current == nil || current.ID != auction.ID {
+		current := s.store.GetAuction(auctionID)
+		if current == nil {
+			return
+		}
+		// A paused auction keeps its countdown goroutine but doesn't advance
+		if current.Status == model.AuctionStatusPaused {
+			continue
+		}
+		if current.Status != model.AuctionStatusActive {
 			return
 		}
 
-		if time.Now().After(current.EndTime) {
-			s.endAuction(current)
+		if s.clock.Now().After(current.EndTime) && s.endAuction(current) {
 			return
 		}
+
+		if current.Type == model.AuctionTypeDutch {
+			s.dropDutchPrice(current)
+		}
 	}
 }
 
-// endAuction marks an auction as ended and broadcasts the event
-func (s *AuctionService) endAuction(auction *model.Auction) {
+// endAuction marks an auction as ended and broadcasts the event. It returns
+// false if a last-second bid extended the auction, or an operator paused it,
+// before the lock was taken.
+func (s *AuctionService) endAuction(auction *model.Auction) bool {
 	s.timerMutex.Lock()
 	defer s.timerMutex.Unlock()
 
-	auction.Status = model.AuctionStatusEnded
-	s.store.SetCurrentAuction(auction)
+	if auction.Status == model.AuctionStatusPaused {
+		return false // keep counting once it is resumed
+	}
+	if auction.Status != model.AuctionStatusActive {
+		return true
+	}
+	if s.clock.Now().Before(auction.EndTime) {
+		return false
+	}
+
+	if err := s.closeAuction(auction, s.clock.Now(), model.NewAuctionEndedEvent); err != nil {
+		// Leave the auction active so the countdown retries on the next tick
+		log.Printf("failed to end auction %s: %v", auction.ID, err)
+		return false
+	}
+	return true
+}
+
+// closeAuction ends an active or paused auction immediately, settles it and
+// broadcasts the event newEvent makes. Callers must hold timerMutex.
+func (s *AuctionService) closeAuction(auction *model.Auction, now time.Time, newEvent func(*model.Auction) *model.AuctionEvent) error {
+	// Without a bid at or above the reserve, nobody wins
+	status := auction.EndStatus()
+	if err := s.commit(eventlog.NewAuctionEndedRecord(auction.ID, now, status), func() error {
+		return s.store.UpdateAuction(auction.ID, func(a *model.Auction) error {
+			a.End(status)
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	s.settle(auction, now)
 
-	// Broadcast auction ended event
-	s.store.Broadcast(model.NewAuctionEndedEvent(auction))
+	s.store.Broadcast(newEvent(auction))
+	return nil
+}
+
+// commit appends a state change to the event log, if one is configured, and
+// then applies it with write. A write that fails is rolled back in the log, so
+// replay never applies a change the caller was told didn't happen. It must be
+// called with timerMutex held so log order matches state order.
+func (s *AuctionService) commit(rec eventlog.Record, write func() error) error {
+	if s.eventLog == nil {
+		return write()
+	}
+	logged, err := s.eventLog.Append(rec)
+	if err != nil {
+		return fmt.Errorf("record %s: %w", rec.Type, err)
+	}
+	if err := write(); err != nil {
+		if _, rollbackErr := s.eventLog.Append(eventlog.NewRolledBackRecord(logged, s.clock.Now())); rollbackErr != nil {
+			log.Printf("failed to roll back %s #%d of auction %s: %v", rec.Type, logged.Sequence, rec.AuctionID, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Subscribe creates a new event subscription. An empty auctionID
+// subscribes to events from every auction.
+func (s *AuctionService) Subscribe(id string, auctionID string) chan *model.AuctionEvent {
+	return s.store.Subscribe(id, auctionID)
+}
+
+// Resume subscribes to a single auction, returning the buffered events after
+// afterSequence. ok is false when the subscriber must resync from scratch.
+func (s *AuctionService) Resume(id string, auctionID string, afterSequence int) (chan *model.AuctionEvent, []*model.AuctionEvent, int, bool) {
+	return s.store.Resume(id, auctionID, afterSequence)
+}
+
+// Follow subscribes to an auction's events, or to every auction's if auctionID
+// is empty, and returns the events to deliver ahead of the live ones. A
+// subscriber resuming after afterSequence gets the events it missed, or a
+// RESYNC_REQUIRED event once they are no longer buffered; a new one gets the
+// current auction state tagged with the latest sequence, so it can resume from
+// there. Resuming needs an auctionID, as sequences are per auction.
+func (s *AuctionService) Follow(id string, auctionID string, afterSequence *int) (chan *model.AuctionEvent, []*model.AuctionEvent) {
+	// Nothing changes while the current state is copied and tagged
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	if afterSequence != nil && auctionID != "" {
+		ch, missed, latest, ok := s.store.Resume(id, auctionID, *afterSequence)
+		if !ok {
+			return ch, []*model.AuctionEvent{model.NewResyncRequiredEvent(s.store.GetAuction(auctionID).Snapshot(), latest)}
+		}
+		return ch, missed
+	}
+
+	ch := s.store.Subscribe(id, auctionID)
+	current := s.store.GetCurrentAuction()
+	if auctionID != "" {
+		current = s.store.GetAuction(auctionID)
+	}
+	if current == nil {
+		return ch, nil
+	}
+	snapshot := model.NewAuctionStartedEvent(current.Snapshot())
+	snapshot.Sequence = s.store.LatestSequence(current.ID)
+	return ch, []*model.AuctionEvent{snapshot}
 }
 
-// Subscribe creates a new event subscription
-func (s *AuctionService) Subscribe(id string) chan *model.AuctionEvent {
-	return s.store.Subscribe(id)
+// LatestSequence returns the sequence number of the most recent event of an auction
+func (s *AuctionService) LatestSequence(auctionID string) int {
+	return s.store.LatestSequence(auctionID)
 }
 
 // Unsubscribe removes an event subscription
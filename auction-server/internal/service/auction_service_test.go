@@ -2,24 +2,33 @@ package service
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
 	"github.com/micahli/fl-auction/auction-server/internal/model"
 	"github.com/micahli/fl-auction/auction-server/internal/store"
 )
 
+// usd returns a whole number of US dollars
+func usd(units int64) model.Money {
+	return model.WholeUnits(units, "USD")
+}
+
 func TestCreateAuction(t *testing.T) {
 	st := store.NewAuctionStore()
 	svc := NewAuctionService(st)
 
-	auction, err := svc.CreateAuction(context.Background(), 100.0, 30, false)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if auction.StartingBid != 100.0 {
-		t.Errorf("expected starting bid 100.0, got %f", auction.StartingBid)
+	if auction.StartingBid != usd(100) {
+		t.Errorf("expected starting bid 100.0, got %s", auction.StartingBid)
 	}
 
 	if auction.Status != model.AuctionStatusActive {
@@ -27,18 +36,35 @@ func TestCreateAuction(t *testing.T) {
 	}
 }
 
-func TestCreateAuction_AlreadyActive(t *testing.T) {
+func TestCreateAuction_Concurrent(t *testing.T) {
 	st := store.NewAuctionStore()
 	svc := NewAuctionService(st)
 
-	_, err := svc.CreateAuction(context.Background(), 100.0, 30, false)
+	first, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
 	if err != nil {
 		t.Fatalf("first auction creation failed: %v", err)
 	}
 
-	_, err = svc.CreateAuction(context.Background(), 100.0, 30, false)
-	if err != model.ErrAuctionAlreadyActive {
-		t.Errorf("expected ErrAuctionAlreadyActive, got %v", err)
+	second, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(200), Duration: 30})
+	if err != nil {
+		t.Fatalf("second auction creation failed: %v", err)
+	}
+
+	if first.ID == second.ID {
+		t.Errorf("expected distinct auction IDs, got %s twice", first.ID)
+	}
+
+	active := model.AuctionStatusActive
+	if got := len(svc.ListAuctions(&active)); got != 2 {
+		t.Errorf("expected 2 active auctions, got %d", got)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), second.ID, "user1", usd(250)); err != nil {
+		t.Fatalf("bid placement failed: %v", err)
+	}
+
+	if first.CurrentBid != usd(100) {
+		t.Errorf("expected first auction to be unaffected, got current bid %s", first.CurrentBid)
 	}
 }
 
@@ -46,18 +72,18 @@ func TestPlaceBid_Success(t *testing.T) {
 	st := store.NewAuctionStore()
 	svc := NewAuctionService(st)
 
-	_, err := svc.CreateAuction(context.Background(), 100.0, 30, false)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
 	if err != nil {
 		t.Fatalf("auction creation failed: %v", err)
 	}
 
-	bid, err := svc.PlaceBid(context.Background(), "user1", 150.0)
+	bid, err := svc.PlaceBid(context.Background(), auction.ID, "user1", usd(150))
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if bid.Amount != 150.0 {
-		t.Errorf("expected bid amount 150.0, got %f", bid.Amount)
+	if bid.Amount != usd(150) {
+		t.Errorf("expected bid amount 150.0, got %s", bid.Amount)
 	}
 
 	if bid.UserID != "user1" {
@@ -69,62 +95,253 @@ func TestPlaceBid_TooLow(t *testing.T) {
 	st := store.NewAuctionStore()
 	svc := NewAuctionService(st)
 
-	_, err := svc.CreateAuction(context.Background(), 100.0, 30, false)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
 	if err != nil {
 		t.Fatalf("auction creation failed: %v", err)
 	}
 
-	_, err = svc.PlaceBid(context.Background(), "user1", 100.0)
+	_, err = svc.PlaceBid(context.Background(), auction.ID, "user1", usd(100))
 	if err == nil {
 		t.Error("expected bid too low error")
 	}
 }
 
-func TestPlaceBid_ExtendedBidding(t *testing.T) {
+func TestPlaceBid_ExactCents(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: model.MustParseMoney("100.10"), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if next := svc.GetNextBid(auction.ID); next != model.MustParseMoney("101.10 USD") {
+		t.Errorf("expected next bid 101.10 USD, got %s", next)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "user1", model.MustParseMoney("101.09")); !errors.Is(err, model.ErrBidTooLow) {
+		t.Errorf("expected ErrBidTooLow for a bid a cent short of the increment, got %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "user1", model.MustParseMoney("101.10")); err != nil {
+		t.Errorf("expected a bid of exactly one increment to be accepted, got %v", err)
+	}
+}
+
+func TestPlaceBid_CurrencyMismatch(t *testing.T) {
 	st := store.NewAuctionStore()
 	svc := NewAuctionService(st)
 
-	auction, err := svc.CreateAuction(context.Background(), 100.0, 5, true)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: model.WholeUnits(100, "EUR"), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if auction.Currency() != "EUR" || auction.CurrentBid != model.WholeUnits(100, "EUR") {
+		t.Errorf("expected a EUR auction, got %s at %s", auction.Currency(), auction.CurrentBid)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "user1", usd(150)); !errors.Is(err, model.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	bid, err := svc.PlaceBid(context.Background(), auction.ID, "user1", model.WholeUnits(150, ""))
+	if err != nil {
+		t.Fatalf("expected a bid without a currency to be accepted, got %v", err)
+	}
+	if bid.Amount != model.WholeUnits(150, "EUR") {
+		t.Errorf("expected bid of 150.00 EUR, got %s", bid.Amount)
+	}
+
+	_, err = svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Currency: "EUR"})
+	if !errors.Is(err, model.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch for a USD starting bid in a EUR auction, got %v", err)
+	}
+}
+
+func TestPlaceBid_UnknownAuction(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	_, err := svc.PlaceBid(context.Background(), "auction-404", "user1", usd(150))
+	if err != model.ErrAuctionNotFound {
+		t.Errorf("expected ErrAuctionNotFound, got %v", err)
+	}
+}
+
+func TestPlaceBid_ExtendedBidding(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, ExtendedBidding: true})
 	if err != nil {
 		t.Fatalf("auction creation failed: %v", err)
 	}
 
 	originalEndTime := auction.EndTime
 
-	// Wait until < 10 seconds remaining
-	time.Sleep(1 * time.Second)
+	// Move to < 10 seconds remaining
+	clk.Advance(25 * time.Second)
 
-	_, err = svc.PlaceBid(context.Background(), "user1", 150.0)
+	_, err = svc.PlaceBid(context.Background(), auction.ID, "user1", usd(150))
 	if err != nil {
 		t.Fatalf("bid placement failed: %v", err)
 	}
 
-	currentAuction := st.GetCurrentAuction()
-	if !currentAuction.EndTime.After(originalEndTime) {
-		t.Error("expected auction to be extended")
+	currentAuction := st.GetAuction(auction.ID)
+	if want := clk.Now().Add(10 * time.Second); !currentAuction.EndTime.Equal(want) {
+		t.Errorf("expected auction to be extended to %v, got %v (was %v)", want, currentAuction.EndTime, originalEndTime)
 	}
 }
 
 func TestAuctionExpiry(t *testing.T) {
 	st := store.NewAuctionStore()
-	svc := NewAuctionService(st)
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
 
-	_, err := svc.CreateAuction(context.Background(), 100.0, 2, false)
+	created, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
 	if err != nil {
 		t.Fatalf("auction creation failed: %v", err)
 	}
+	events := svc.Subscribe("test", created.ID)
 
-	// Wait for auction to expire
-	time.Sleep(3 * time.Second)
+	// Let the countdown run out
+	clk.BlockUntil(1)
+	clk.Advance(31 * time.Second)
+	if event := <-events; event.Type != model.EventAuctionEnded {
+		t.Fatalf("expected AUCTION_ENDED, got %s", event.Type)
+	}
 
-	auction := st.GetCurrentAuction()
+	auction := st.GetAuction(created.ID)
 	if auction.Status != model.AuctionStatusEnded {
 		t.Errorf("expected status ENDED, got %s", auction.Status)
 	}
 
 	// Try to place bid on ended auction
-	_, err = svc.PlaceBid(context.Background(), "user1", 150.0)
+	_, err = svc.PlaceBid(context.Background(), created.ID, "user1", usd(150))
 	if err != model.ErrNoActiveAuction {
 		t.Errorf("expected ErrNoActiveAuction, got %v", err)
 	}
 }
+
+func TestCreateAuction_ReserveBelowStartingBid(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	reserve := usd(50)
+	_, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, ReservePrice: &reserve})
+	if err != model.ErrInvalidReservePrice {
+		t.Errorf("expected ErrInvalidReservePrice, got %v", err)
+	}
+}
+
+func TestEndAuction_ReserveNotMet(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	reserve := usd(500)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, ReservePrice: &reserve})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "user1", usd(150)); err != nil {
+		t.Fatalf("bid placement failed: %v", err)
+	}
+	if auction.ReserveMet() {
+		t.Error("expected reserve not to be met")
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	if auction.Status != model.AuctionStatusReserveNotMet {
+		t.Errorf("expected status RESERVE_NOT_MET, got %s", auction.Status)
+	}
+	if auction.CurrentWinner != nil {
+		t.Errorf("expected no winner, got %s", *auction.CurrentWinner)
+	}
+}
+
+func TestEndAuction_ReserveMet(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	reserve := usd(500)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, ReservePrice: &reserve})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	// A maximum above the reserve bids straight up to it
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "user1", usd(600)); err != nil {
+		t.Fatalf("max bid placement failed: %v", err)
+	}
+	if auction.CurrentBid != usd(500) || !auction.ReserveMet() {
+		t.Errorf("expected reserve to be met at 500.0, got %s", auction.CurrentBid)
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	if auction.Status != model.AuctionStatusEnded {
+		t.Errorf("expected status ENDED, got %s", auction.Status)
+	}
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "user1" {
+		t.Errorf("expected user1 to win, got %v", auction.CurrentWinner)
+	}
+}
+
+// failingRepository is a memory repository whose bid writes fail while err is set
+type failingRepository struct {
+	*store.MemoryRepository
+	err error
+}
+
+func (r *failingRepository) AddBid(bid *model.Bid) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.MemoryRepository.AddBid(bid)
+}
+
+func TestPlaceBid_FailedWriteIsRolledBackInLog(t *testing.T) {
+	l, err := eventlog.Open(filepath.Join(t.TempDir(), "events.log"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer l.Close()
+	repo := &failingRepository{MemoryRepository: store.NewMemoryRepository()}
+	users := NewUserService(store.NewMemoryRepository(), WithAutoVerify(), WithStartingCredit(usd(1000)))
+	if _, err := users.RegisterBidder(context.Background(), "alice", "Alice"); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	svc := NewAuctionService(store.NewAuctionStoreWithRepository(repo), WithEventLog(l), WithBidderRegistry(users), WithCreditLedger(users))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	repo.err = errors.New("disk full")
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); !errors.Is(err, repo.err) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	if got := users.GetUser("alice").HoldOn(auction.ID); got.IsPositive() {
+		t.Errorf("expected the hold to be undone, got %s", got)
+	}
+	repo.err = nil
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(120)); err != nil {
+		t.Fatalf("bid placement failed: %v", err)
+	}
+
+	records, err := l.Records()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	auctions, err := eventlog.Replay(records)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if replayed := auctions[0]; len(replayed.Bids) != 1 || replayed.CurrentBid != usd(120) {
+		t.Errorf("expected replay to keep only the 120.0 bid clients were told about, got %+v", replayed.Bids)
+	}
+}
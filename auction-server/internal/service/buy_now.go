@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// BuyNow ends the auction at once with the user as winner at the buy-now
+// price. It takes timerMutex like PlaceBid, so it either beats a last-second
+// bid or sees it and is refused if that bid reached the cutoff.
+func (s *AuctionService) BuyNow(ctx context.Context, auctionID string, userID string) (*model.Auction, error) {
+	if err := s.checkBidder(userID); err != nil {
+		return nil, err
+	}
+
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	now := s.clock.Now()
+	auction, err := s.biddableAuction(auctionID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !s.validationRule.BuyNowAvailable(auction) {
+		return nil, model.ErrBuyNowUnavailable
+	}
+
+	if _, err := s.sellNow(auction, userID, *auction.BuyNowPrice, now); err != nil {
+		return nil, err
+	}
+	return auction, nil
+}
+
+// BuyNowAvailable reports whether the auction can still be bought outright
+func (s *AuctionService) BuyNowAvailable(auction *model.Auction) bool {
+	return s.validationRule.BuyNowAvailable(auction)
+}
+
+// sellNow records a winning bid at price and closes the auction immediately,
+// broadcasting BID_PLACED followed by AUCTION_ENDED. Callers must hold timerMutex.
+func (s *AuctionService) sellNow(auction *model.Auction, userID string, price model.Money, now time.Time) (*model.Bid, error) {
+	undo, err := s.holdFunds(auction, userID, price)
+	if err != nil {
+		return nil, err
+	}
+
+	bid := &model.Bid{
+		ID:        fmt.Sprintf("bid-%d", s.store.GetNextBidID()),
+		AuctionID: auction.ID,
+		UserID:    userID,
+		Amount:    price,
+		Quantity:  1,
+		Timestamp: now,
+	}
+	if err := s.commit(eventlog.NewBidAcceptedRecord(bid), func() error {
+		return s.store.AddBid(bid)
+	}); err != nil {
+		undo()
+		return nil, err
+	}
+	s.store.Broadcast(model.NewBidPlacedEvent(auction, bid))
+
+	if err := s.closeAuction(auction, now, model.NewAuctionEndedEvent); err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
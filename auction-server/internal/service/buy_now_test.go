@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestBuyNow_EndsAuction(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	buyNow := usd(300)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, BuyNowPrice: &buyNow})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	events := svc.Subscribe("test", auction.ID)
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(120)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if _, err := svc.BuyNow(context.Background(), auction.ID, "bob"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if auction.Status != model.AuctionStatusEnded {
+		t.Errorf("expected status ENDED, got %s", auction.Status)
+	}
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "bob" || auction.CurrentBid != usd(300) {
+		t.Errorf("expected bob to win at 300.0, got %v at %s", auction.CurrentWinner, auction.CurrentBid)
+	}
+
+	var last *model.AuctionEvent
+	for len(events) > 0 {
+		last = <-events
+	}
+	if last == nil || last.Type != model.EventAuctionEnded {
+		t.Errorf("expected AUCTION_ENDED as the last event, got %+v", last)
+	}
+}
+
+func TestBuyNow_DisabledAtCutoff(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st, WithBuyNowCutoff(40))
+
+	buyNow := usd(300)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, BuyNowPrice: &buyNow})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(110)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if !svc.BuyNowAvailable(auction) {
+		t.Fatal("expected buy-now to be available below the cutoff")
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(120)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if _, err := svc.BuyNow(context.Background(), auction.ID, "bob"); !errors.Is(err, model.ErrBuyNowUnavailable) {
+		t.Errorf("expected ErrBuyNowUnavailable, got %v", err)
+	}
+	if auction.Status != model.AuctionStatusActive {
+		t.Errorf("expected auction to stay active, got %s", auction.Status)
+	}
+}
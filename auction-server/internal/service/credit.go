@@ -0,0 +1,137 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// CreditLedger holds bidders' credit against the auctions they bid on
+type CreditLedger interface {
+	// Hold replaces the user's hold on an auction with amount, returning the
+	// previous hold. It fails if their available credit doesn't cover it.
+	Hold(userID string, auctionID string, amount model.Money) (previous model.Money, err error)
+	// Release drops the user's hold on an auction, if they have one
+	Release(userID string, auctionID string) error
+	// Settle charges each winner of a closed auction and releases every other hold on it
+	Settle(auctionID string, charges map[string]model.Money, at time.Time) error
+}
+
+// WithCreditLedger makes every bid hold the bidder's credit: bids their
+// available credit doesn't cover are refused, outbid bidders get their hold
+// back and winners are charged when the auction ends
+func WithCreditLedger(l CreditLedger) Option {
+	return func(s *AuctionService) {
+		s.credit = l
+	}
+}
+
+// holdFunds holds amount of the user's credit for the auction before their bid
+// is accepted. undo restores the previous hold if accepting the bid then fails.
+// Callers must hold timerMutex.
+func (s *AuctionService) holdFunds(auction *model.Auction, userID string, amount model.Money) (undo func(), err error) {
+	if s.credit == nil {
+		return func() {}, nil
+	}
+	previous, err := s.credit.Hold(userID, auction.ID, amount)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if _, err := s.credit.Hold(userID, auction.ID, previous); err != nil {
+			log.Printf("failed to restore hold of %s on auction %s: %v", userID, auction.ID, err)
+		}
+	}, nil
+}
+
+// requiredHold returns how much credit a bid commits the user to: a multi-unit
+// bid commits every unit, and an English bid never less than the user's own
+// maximum, which the server may still bid up to on their behalf. A bid whose
+// units cost more than any amount can hold is an invalid amount.
+func requiredHold(auction *model.Auction, userID string, amount model.Money, quantity int) (model.Money, error) {
+	if auction.IsMultiUnit() {
+		total, err := amount.Times(quantity)
+		if err != nil {
+			return model.Money{}, model.ErrInvalidBidAmount
+		}
+		return total, nil
+	}
+	if proxy := auction.ProxyFor(userID); proxy != nil && !auction.IsSealed() {
+		return model.MaxMoney(amount, proxy.MaxAmount), nil
+	}
+	return amount, nil
+}
+
+// releaseOutbid returns the holds of bidders who can no longer win: in an
+// English auction the previous leader once outbid, unless their maximum still
+// covers the next bid, and anyone whose maximum has been overtaken; in a
+// multi-unit lot every bidder left without units. Callers must hold timerMutex.
+func (s *AuctionService) releaseOutbid(auction *model.Auction, previousLeader string) {
+	if s.credit == nil || auction.IsSealed() || auction.Type == model.AuctionTypeDutch {
+		return
+	}
+	if auction.IsMultiUnit() {
+		s.releaseUnallocated(auction)
+		return
+	}
+	leader := ""
+	if auction.CurrentWinner != nil {
+		leader = *auction.CurrentWinner
+	}
+	nextMinimum := s.validationRule.CalculateNextMinimumBid(auction.CurrentBid, auction.Increments)
+
+	outbid := make([]string, 0, len(auction.ProxyBids)+1)
+	if previousLeader != "" && previousLeader != leader {
+		if proxy := auction.ProxyFor(previousLeader); proxy == nil || proxy.MaxAmount.Cmp(nextMinimum) < 0 {
+			outbid = append(outbid, previousLeader)
+		}
+	}
+	for _, proxy := range auction.ProxyBids {
+		if proxy.UserID != leader && proxy.UserID != previousLeader && proxy.MaxAmount.Cmp(nextMinimum) < 0 {
+			outbid = append(outbid, proxy.UserID)
+		}
+	}
+
+	for _, userID := range outbid {
+		// The bid stands either way; a stale hold only leaves the bidder less to spend
+		if err := s.credit.Release(userID, auction.ID); err != nil {
+			log.Printf("failed to release hold of %s on auction %s: %v", userID, auction.ID, err)
+		}
+	}
+}
+
+// releaseUnallocated returns the holds of a multi-unit lot's bidders whose
+// standing bid wins no units in the provisional allocation. Callers must hold
+// timerMutex.
+func (s *AuctionService) releaseUnallocated(auction *model.Auction) {
+	winning := make(map[string]bool)
+	for _, a := range auction.Allocate() {
+		winning[a.UserID] = true
+	}
+	for _, bid := range auction.StandingBids() {
+		if winning[bid.UserID] {
+			continue
+		}
+		if err := s.credit.Release(bid.UserID, auction.ID); err != nil {
+			log.Printf("failed to release hold of %s on auction %s: %v", bid.UserID, auction.ID, err)
+		}
+	}
+}
+
+// settle charges the winners of an auction that has just closed and releases
+// everyone else's hold; a cancelled auction charges nobody. Callers must hold
+// timerMutex.
+func (s *AuctionService) settle(auction *model.Auction, now time.Time) {
+	if s.credit == nil {
+		return
+	}
+	charges, err := auction.Charges()
+	if err != nil {
+		log.Printf("failed to settle auction %s: %v", auction.ID, err)
+		return
+	}
+	if err := s.credit.Settle(auction.ID, charges, now); err != nil {
+		log.Printf("failed to settle auction %s: %v", auction.ID, err)
+	}
+}
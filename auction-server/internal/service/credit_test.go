@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+// newCreditService returns an auction service whose bids hold credit, with
+// verified bidders alice, bob and carol who have deposited the given amounts
+func newCreditService(t *testing.T, clk clock.Clock, credit map[string]int64) (*AuctionService, *UserService) {
+	t.Helper()
+	users := NewUserService(store.NewMemoryRepository(), WithAutoVerify(), WithUserClock(clk))
+	for _, id := range []string{"alice", "bob", "carol"} {
+		if _, err := users.RegisterBidder(context.Background(), id, id); err != nil {
+			t.Fatalf("registering %s failed: %v", id, err)
+		}
+		if credit[id] > 0 {
+			if _, err := users.DepositCredit(context.Background(), id, usd(credit[id])); err != nil {
+				t.Fatalf("deposit for %s failed: %v", id, err)
+			}
+		}
+	}
+	svc := NewAuctionService(store.NewAuctionStore(), WithClock(clk), WithBidderRegistry(users), WithCreditLedger(users))
+	return svc, users
+}
+
+func TestPlaceBid_HoldsCreditAndReleasesOutbid(t *testing.T) {
+	svc, users := newCreditService(t, clock.Real, map[string]int64{"alice": 1000, "bob": 1000})
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if got := users.GetUser("alice").Available("USD"); got != usd(850) {
+		t.Errorf("expected alice to have 850.0 available, got %s", got)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(200)); err != nil {
+		t.Fatalf("bob's bid failed: %v", err)
+	}
+	if got := users.GetUser("bob").HoldOn(auction.ID); got != usd(200) {
+		t.Errorf("expected bob to hold 200.0, got %s", got)
+	}
+	if alice := users.GetUser("alice"); len(alice.Holds) != 0 || alice.Available("USD") != usd(1000) {
+		t.Errorf("expected alice's hold released once outbid, got %+v", alice.Holds)
+	}
+}
+
+func TestPlaceBid_InsufficientCredit(t *testing.T) {
+	svc, users := newCreditService(t, clock.Real, map[string]int64{"alice": 1000, "carol": 100})
+
+	other, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	_, err = svc.PlaceBid(context.Background(), auction.ID, "carol", usd(150))
+	var creditErr *model.CreditError
+	if !errors.As(err, &creditErr) || !errors.Is(err, model.ErrInsufficientCredit) {
+		t.Fatalf("expected a CreditError, got %v", err)
+	}
+	if creditErr.Required != usd(150) || creditErr.Available != usd(100) {
+		t.Errorf("expected 150.0 required and 100.0 available, got %+v", creditErr)
+	}
+	if len(auction.Bids) != 0 || len(users.GetUser("carol").Holds) != 0 {
+		t.Errorf("expected no bid and no hold, got %d bids and %+v", len(auction.Bids), users.GetUser("carol").Holds)
+	}
+
+	// Credit held for one auction can't be spent on another
+	if _, err := svc.PlaceBid(context.Background(), other.ID, "alice", usd(700)); err != nil {
+		t.Fatalf("alice's first bid failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(400)); !errors.Is(err, model.ErrInsufficientCredit) {
+		t.Errorf("expected ErrInsufficientCredit beyond the unheld 300.0, got %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), other.ID, "alice", usd(1000)); err != nil {
+		t.Errorf("expected alice to raise her own bid using the credit it already holds, got %v", err)
+	}
+}
+
+func TestPlaceMaxBid_HoldsMaximum(t *testing.T) {
+	svc, users := newCreditService(t, clock.Real, map[string]int64{"alice": 1000, "bob": 1000})
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(300)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(200)); err != nil {
+		t.Fatalf("bob's bid failed: %v", err)
+	}
+	if *auction.CurrentWinner != "alice" || users.GetUser("alice").HoldOn(auction.ID) != usd(300) {
+		t.Errorf("expected alice to lead holding her 300.0 maximum, got %v holding %s", *auction.CurrentWinner, users.GetUser("alice").HoldOn(auction.ID))
+	}
+	if len(users.GetUser("bob").Holds) != 0 {
+		t.Errorf("expected bob's hold released once the proxy outbid him, got %+v", users.GetUser("bob").Holds)
+	}
+
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "bob", usd(400)); err != nil {
+		t.Fatalf("bob's maximum failed: %v", err)
+	}
+	if *auction.CurrentWinner != "bob" || users.GetUser("bob").HoldOn(auction.ID) != usd(400) {
+		t.Errorf("expected bob to lead holding 400.0, got %v holding %s", *auction.CurrentWinner, users.GetUser("bob").HoldOn(auction.ID))
+	}
+	if len(users.GetUser("alice").Holds) != 0 {
+		t.Errorf("expected alice's hold released once her maximum was overtaken, got %+v", users.GetUser("alice").Holds)
+	}
+}
+
+func TestEndAuction_SettlesWinner(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc, users := newCreditService(t, clk, map[string]int64{"alice": 1000, "bob": 1000})
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "bob", usd(500)); err != nil {
+		t.Fatalf("bob's maximum failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	bob := users.GetUser("bob")
+	if len(bob.Holds) != 0 || bob.Balance("USD") != usd(849) {
+		t.Errorf("expected bob charged his 151.0 winning bid, got balance %s and holds %+v", bob.Balance("USD"), bob.Holds)
+	}
+	if len(bob.Settlements) != 1 || bob.Settlements[0].AuctionID != auction.ID || bob.Settlements[0].Amount != usd(151) {
+		t.Errorf("expected a settlement of 151.0, got %+v", bob.Settlements)
+	}
+	if alice := users.GetUser("alice"); alice.Balance("USD") != usd(1000) || len(alice.Settlements) != 0 {
+		t.Errorf("expected alice not to be charged, got %+v", alice)
+	}
+}
+
+func TestCancelAuction_ReleasesHolds(t *testing.T) {
+	svc, users := newCreditService(t, clock.Real, map[string]int64{"alice": 1000})
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(400)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+	if _, err := svc.CancelAuction(context.Background(), auction.ID, ""); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+
+	alice := users.GetUser("alice")
+	if len(alice.Holds) != 0 || len(alice.Settlements) != 0 || alice.Balance("USD") != usd(1000) {
+		t.Errorf("expected alice's hold released without a charge, got %+v", alice)
+	}
+}
+
+func TestPlaceBid_ReleasesBiddersLeftWithoutUnits(t *testing.T) {
+	svc, users := newCreditService(t, clock.Real, map[string]int64{"alice": 1000, "bob": 1000, "carol": 1000})
+
+	lot, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, Quantity: 3})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceBidForQuantity(context.Background(), lot.ID, "alice", usd(110), 2); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if _, err := svc.PlaceBidForQuantity(context.Background(), lot.ID, "bob", usd(120), 1); err != nil {
+		t.Fatalf("bob's bid failed: %v", err)
+	}
+	if got := users.GetUser("alice").HoldOn(lot.ID); got != usd(220) {
+		t.Errorf("expected alice's 2 units at 110.0 to hold 220.0, got %s", got)
+	}
+
+	// carol's 2 units leave alice with none, while bob keeps his
+	if _, err := svc.PlaceBidForQuantity(context.Background(), lot.ID, "carol", usd(130), 2); err != nil {
+		t.Fatalf("carol's bid failed: %v", err)
+	}
+	if got := users.GetUser("alice").HoldOn(lot.ID); got.IsPositive() {
+		t.Errorf("expected alice's hold to be released, got %s", got)
+	}
+	if got := users.GetUser("bob").HoldOn(lot.ID); got != usd(120) {
+		t.Errorf("expected bob to keep his hold of 120.0, got %s", got)
+	}
+	if got := users.GetUser("carol").HoldOn(lot.ID); got != usd(260) {
+		t.Errorf("expected carol to hold 260.0, got %s", got)
+	}
+}
+
+func TestSettlement_SealedAndMultiUnit(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc, users := newCreditService(t, clk, map[string]int64{"alice": 1000, "bob": 1000, "carol": 1000})
+
+	sealed, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid: usd(100),
+		Duration:    30,
+		Type:        model.AuctionTypeVickrey,
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	lot, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, Quantity: 6})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	// Every sealed bid holds credit until close, not just the highest
+	if _, err := svc.PlaceBid(context.Background(), sealed.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("alice's sealed bid failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), sealed.ID, "bob", usd(120)); err != nil {
+		t.Fatalf("bob's sealed bid failed: %v", err)
+	}
+	if users.GetUser("bob").HoldOn(sealed.ID) != usd(120) {
+		t.Errorf("expected bob's sealed bid to hold 120.0, got %s", users.GetUser("bob").HoldOn(sealed.ID))
+	}
+
+	// A multi-unit bid holds the price of every unit
+	placeLotBids(t, svc, lot.ID)
+	if got := users.GetUser("alice").HoldOn(lot.ID); got != usd(450) {
+		t.Errorf("expected alice's 3 units at 150.0 to hold 450.0, got %s", got)
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(sealed)
+	svc.endAuction(lot)
+
+	// alice pays bob's 120 for the sealed lot and 3 × 120 for the multi-unit lot
+	want := map[string]int64{"alice": 1000 - 120 - 360, "bob": 1000 - 240, "carol": 1000 - 120}
+	for id, balance := range want {
+		user := users.GetUser(id)
+		if len(user.Holds) != 0 || user.Balance("USD") != usd(balance) {
+			t.Errorf("%s: expected balance %s and no holds, got %s and %+v", id, usd(balance), user.Balance("USD"), user.Holds)
+		}
+	}
+}
+
+func TestPlaceBid_RefusesAmountsBeyondTheLimit(t *testing.T) {
+	svc, users := newCreditService(t, clock.Real, map[string]int64{"alice": 1000})
+
+	if _, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, Quantity: 1000001}); !errors.Is(err, model.ErrInvalidQuantity) {
+		t.Errorf("expected ErrInvalidQuantity for an oversized lot, got %v", err)
+	}
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, Quantity: 2})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	// Two units at this price would wrap around to a negative hold
+	huge := model.MustParseMoney("50000000000000000")
+	if _, err := svc.PlaceBidForQuantity(context.Background(), auction.ID, "alice", huge, 2); !errors.Is(err, model.ErrInvalidBidAmount) {
+		t.Errorf("expected ErrInvalidBidAmount, got %v", err)
+	}
+	if alice := users.GetUser("alice"); len(alice.Holds) != 0 || alice.Available("USD") != usd(1000) {
+		t.Errorf("expected alice's credit untouched, got %+v", alice)
+	}
+	if auction.HasBids() {
+		t.Errorf("expected no bid to be accepted, got %+v", auction.Bids)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// newDutchSchedule validates the price drop settings of a new Dutch auction
+func (s *AuctionService) newDutchSchedule(params CreateAuctionParams, now time.Time) (*model.DutchSchedule, error) {
+	// A Dutch auction sells to the first taker, so there is nothing to extend
+	// and the floor price plays the role of a reserve
+	if params.ExtendedBidding || params.ReservePrice != nil {
+		return nil, model.ErrUnsupportedForType
+	}
+	if params.PriceDropAmount == nil || params.PriceDropInterval == nil {
+		return nil, model.ErrInvalidPriceDrop
+	}
+
+	floor := model.NewMoney(s.validationRule.MinStartingBid.Cents, params.Currency)
+	if params.FloorPrice != nil {
+		floor = *params.FloorPrice
+	}
+	if err := s.validationRule.ValidateDutchSchedule(*params.PriceDropAmount, *params.PriceDropInterval, floor, params.StartingBid); err != nil {
+		return nil, err
+	}
+
+	return &model.DutchSchedule{
+		DropAmount:   *params.PriceDropAmount,
+		DropInterval: *params.PriceDropInterval,
+		FloorPrice:   floor,
+		NextDropAt:   now.Add(time.Duration(*params.PriceDropInterval) * time.Second),
+	}, nil
+}
+
+// acceptDutchBid sells a Dutch auction to the first bid at or above the asking
+// price. The bidder pays the asking price. Callers must hold timerMutex.
+func (s *AuctionService) acceptDutchBid(auction *model.Auction, userID string, amount model.Money, now time.Time) (*model.Bid, error) {
+	if err := s.validationRule.ValidateBidLimit(amount); err != nil {
+		return nil, err
+	}
+	if amount.Cmp(auction.CurrentBid) < 0 {
+		return nil, model.NewBidTooLowError(auction.CurrentBid, amount)
+	}
+	return s.sellNow(auction, userID, auction.CurrentBid, now)
+}
+
+// dropDutchPrice lowers the asking price of a Dutch auction for every drop
+// that is due, broadcasting PRICE_DROPPED for each one
+func (s *AuctionService) dropDutchPrice(auction *model.Auction) {
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	now := s.clock.Now()
+	for auction.Status == model.AuctionStatusActive && auction.DutchSchedule != nil {
+		schedule := *auction.DutchSchedule
+		if now.Before(schedule.NextDropAt) || auction.CurrentBid.Cmp(schedule.FloorPrice) <= 0 {
+			return
+		}
+
+		price := schedule.NextPrice(auction.CurrentBid)
+		schedule.NextDropAt = schedule.NextDropAt.Add(time.Duration(schedule.DropInterval) * time.Second)
+		if err := s.commit(eventlog.NewPriceDroppedRecord(auction.ID, now, price, schedule.NextDropAt), func() error {
+			return s.store.UpdateAuction(auction.ID, func(a *model.Auction) error {
+				a.CurrentBid = price
+				a.DutchSchedule = &schedule
+				return nil
+			})
+		}); err != nil {
+			log.Printf("failed to drop price of auction %s: %v", auction.ID, err)
+			return
+		}
+
+		s.store.Broadcast(model.NewPriceDroppedEvent(auction))
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func newDutchParams() CreateAuctionParams {
+	drop, interval := usd(10), 5
+	return CreateAuctionParams{
+		StartingBid:       usd(100),
+		Duration:          60,
+		Type:              model.AuctionTypeDutch,
+		PriceDropAmount:   &drop,
+		PriceDropInterval: &interval,
+	}
+}
+
+func TestDutchAuction_PriceDrops(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	floor := usd(75)
+	params := newDutchParams()
+	params.FloorPrice = &floor
+	auction, err := svc.CreateAuction(context.Background(), params)
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	events := svc.Subscribe("test", auction.ID)
+
+	// Three drops are due, but the floor stops the third one short
+	clk.Advance(15 * time.Second)
+	svc.dropDutchPrice(auction)
+
+	if auction.CurrentBid != usd(75) {
+		t.Errorf("expected asking price 75.0, got %s", auction.CurrentBid)
+	}
+	if got := len(events); got != 3 {
+		t.Errorf("expected 3 PRICE_DROPPED events, got %d", got)
+	}
+	if next := svc.GetNextBid(auction.ID); next != usd(75) {
+		t.Errorf("expected next bid 75.0, got %s", next)
+	}
+}
+
+func TestDutchAuction_FirstBidWins(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), newDutchParams())
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(90)); !errors.Is(err, model.ErrBidTooLow) {
+		t.Errorf("expected ErrBidTooLow below the asking price, got %v", err)
+	}
+
+	bid, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(120))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bid.Amount != usd(100) {
+		t.Errorf("expected bob to pay the asking price of 100.0, got %s", bid.Amount)
+	}
+	if auction.Status != model.AuctionStatusEnded {
+		t.Errorf("expected auction to end, got %s", auction.Status)
+	}
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "bob" {
+		t.Errorf("expected bob to win, got %v", auction.CurrentWinner)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "carol", usd(150)); !errors.Is(err, model.ErrNoActiveAuction) {
+		t.Errorf("expected ErrNoActiveAuction after the sale, got %v", err)
+	}
+}
+
+func TestDutchAuction_InvalidSchedule(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	floor := usd(150)
+	params := newDutchParams()
+	params.FloorPrice = &floor
+	if _, err := svc.CreateAuction(context.Background(), params); !errors.Is(err, model.ErrInvalidPriceDrop) {
+		t.Errorf("expected ErrInvalidPriceDrop for a floor above the starting price, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestExtensionPolicy_ExtendBy(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid:     usd(100),
+		Duration:        30,
+		ExtensionPolicy: &model.ExtensionPolicy{Mode: model.ExtensionExtendBy, Threshold: 10, Duration: 15},
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if !auction.ExtendedBidding {
+		t.Error("expected an extension policy to turn on extended bidding")
+	}
+	original := auction.EndTime
+	events := svc.Subscribe("test", auction.ID)
+
+	clk.Advance(25 * time.Second)
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("bid failed: %v", err)
+	}
+
+	if want := original.Add(15 * time.Second); !auction.EndTime.Equal(want) {
+		t.Errorf("expected end time %v, got %v", want, auction.EndTime)
+	}
+	if !auction.OriginalEndTime.Equal(original) || auction.Extensions != 1 {
+		t.Errorf("expected original end %v after 1 extension, got %v after %d", original, auction.OriginalEndTime, auction.Extensions)
+	}
+	if event := <-events; event.Type != model.EventBidPlaced {
+		t.Errorf("expected BID_PLACED first, got %s", event.Type)
+	}
+	if event := <-events; event.Type != model.EventAuctionExtended {
+		t.Errorf("expected AUCTION_EXTENDED, got %s", event.Type)
+	}
+}
+
+func TestExtensionPolicy_ResetNeverShortens(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid:     usd(100),
+		Duration:        30,
+		ExtensionPolicy: &model.ExtensionPolicy{Mode: model.ExtensionResetTo, Threshold: 20, Duration: 5},
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	original := auction.EndTime
+	events := svc.Subscribe("test", auction.ID)
+
+	// 15 seconds left is inside the threshold, but resetting to 5 would cut it short
+	clk.Advance(15 * time.Second)
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("bid failed: %v", err)
+	}
+	if !auction.EndTime.Equal(original) || auction.Extensions != 0 {
+		t.Errorf("expected end time to stay at %v, got %v", original, auction.EndTime)
+	}
+
+	clk.Advance(13 * time.Second)
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(160)); err != nil {
+		t.Fatalf("bid failed: %v", err)
+	}
+	if want := clk.Now().Add(5 * time.Second); !auction.EndTime.Equal(want) {
+		t.Errorf("expected end time reset to %v, got %v", want, auction.EndTime)
+	}
+
+	var types []model.AuctionEventType
+	for len(events) > 0 {
+		types = append(types, (<-events).Type)
+	}
+	if len(types) != 3 || types[2] != model.EventAuctionExtended {
+		t.Errorf("expected two BID_PLACED and one AUCTION_EXTENDED, got %v", types)
+	}
+}
+
+func TestExtensionPolicy_Caps(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	countCapped, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid:     usd(100),
+		Duration:        30,
+		ExtensionPolicy: &model.ExtensionPolicy{Mode: model.ExtensionExtendBy, Threshold: 10, Duration: 15, MaxExtensions: intPtr(1)},
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	durationCapped, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid:     usd(100),
+		Duration:        30,
+		ExtensionPolicy: &model.ExtensionPolicy{Mode: model.ExtensionExtendBy, Threshold: 10, Duration: 15, MaxTotalExtension: intPtr(20)},
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	clk.Advance(25 * time.Second)
+	for i, amount := range []int64{150, 160} {
+		for _, auction := range []*model.Auction{countCapped, durationCapped} {
+			if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(amount)); err != nil {
+				t.Fatalf("bid %d failed: %v", i+1, err)
+			}
+		}
+		// Both auctions were pushed back 15 seconds; move into the last 10 again
+		clk.Advance(11 * time.Second)
+	}
+
+	if want := countCapped.OriginalEndTime.Add(15 * time.Second); !countCapped.EndTime.Equal(want) || countCapped.Extensions != 1 {
+		t.Errorf("expected a single extension to %v, got %v after %d", want, countCapped.EndTime, countCapped.Extensions)
+	}
+	if want := durationCapped.OriginalEndTime.Add(20 * time.Second); !durationCapped.EndTime.Equal(want) || durationCapped.Extensions != 2 {
+		t.Errorf("expected extensions capped at %v, got %v after %d", want, durationCapped.EndTime, durationCapped.Extensions)
+	}
+}
+
+func TestCreateAuction_InvalidExtensionPolicy(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	policies := map[string]model.ExtensionPolicy{
+		"unknown mode":       {Mode: "DOUBLE", Threshold: 10, Duration: 10},
+		"no threshold":       {Mode: model.ExtensionExtendBy, Duration: 10},
+		"no duration":        {Mode: model.ExtensionResetTo, Threshold: 10},
+		"zero extensions":    {Mode: model.ExtensionExtendBy, Threshold: 10, Duration: 10, MaxExtensions: intPtr(0)},
+		"negative total cap": {Mode: model.ExtensionExtendBy, Threshold: 10, Duration: 10, MaxTotalExtension: intPtr(-5)},
+	}
+	for name, policy := range policies {
+		_, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), ExtensionPolicy: &policy})
+		if !errors.Is(err, model.ErrInvalidExtensionPolicy) {
+			t.Errorf("%s: expected ErrInvalidExtensionPolicy, got %v", name, err)
+		}
+	}
+}
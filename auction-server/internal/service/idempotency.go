@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// DefaultIdempotencyWindow is how long the outcome of a keyed bid is remembered
+// unless WithIdempotencyWindow says otherwise
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// WithIdempotencyWindow sets how long the outcome of a bid placed with an
+// idempotency key is remembered for retries
+func WithIdempotencyWindow(d time.Duration) Option {
+	return func(s *AuctionService) {
+		s.idempotency.window = d
+	}
+}
+
+// outcomeKey scopes a client-supplied idempotency key to the user who sent it,
+// so two bidders can't collide on the same key
+type outcomeKey struct {
+	userID string
+	key    string
+}
+
+// bidRequest is what a keyed bid asked for; a retry must ask for the same
+type bidRequest struct {
+	auctionID string
+	amount    model.Money
+	quantity  int
+}
+
+// bidOutcome is the remembered result of a keyed bid
+type bidOutcome struct {
+	key       outcomeKey
+	request   bidRequest
+	bid       *model.Bid
+	err       error
+	expiresAt time.Time
+}
+
+// idempotencyCache remembers keyed bid outcomes for a fixed window. Every
+// outcome lives equally long, so expiry order is insertion order. It is
+// guarded by timerMutex.
+type idempotencyCache struct {
+	window   time.Duration
+	outcomes map[outcomeKey]*bidOutcome
+	order    []*bidOutcome
+}
+
+func newIdempotencyCache(window time.Duration) idempotencyCache {
+	return idempotencyCache{window: window, outcomes: make(map[outcomeKey]*bidOutcome)}
+}
+
+// lookup returns the unexpired outcome remembered for the key, if any
+func (c *idempotencyCache) lookup(key outcomeKey, now time.Time) (*bidOutcome, bool) {
+	c.expire(now)
+	outcome, ok := c.outcomes[key]
+	return outcome, ok
+}
+
+// remember stores the outcome of a keyed bid until the window passes
+func (c *idempotencyCache) remember(outcome *bidOutcome, now time.Time) {
+	outcome.expiresAt = now.Add(c.window)
+	c.outcomes[outcome.key] = outcome
+	c.order = append(c.order, outcome)
+}
+
+// expire forgets the outcomes whose window has passed
+func (c *idempotencyCache) expire(now time.Time) {
+	n := 0
+	for n < len(c.order) && !now.Before(c.order[n].expiresAt) {
+		if expired := c.order[n]; c.outcomes[expired.key] == expired {
+			delete(c.outcomes, expired.key)
+		}
+		n++
+	}
+	c.order = c.order[n:]
+}
+
+// PlaceBidIdempotent places a bid like PlaceBidForQuantity, but remembers the
+// outcome under the user's idempotency key. A retry with the same key inside
+// the window gets the original bid or error back instead of bidding again; a
+// retry that asks for a different bid is refused. Without a key it's just
+// PlaceBidForQuantity.
+func (s *AuctionService) PlaceBidIdempotent(ctx context.Context, idempotencyKey string, auctionID string, userID string, amount model.Money, quantity int) (*model.Bid, error) {
+	if idempotencyKey == "" {
+		return s.PlaceBidForQuantity(ctx, auctionID, userID, amount, quantity)
+	}
+	if err := s.validationRule.ValidateIdempotencyKey(idempotencyKey); err != nil {
+		return nil, err
+	}
+
+	// Holding timerMutex for the whole bid makes a retry that races the
+	// original wait for its outcome rather than bid a second time
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	now := s.clock.Now()
+	key := outcomeKey{userID: userID, key: idempotencyKey}
+	request := bidRequest{auctionID: auctionID, amount: amount, quantity: quantity}
+	if outcome, ok := s.idempotency.lookup(key, now); ok {
+		if outcome.request != request {
+			return nil, model.ErrIdempotencyKeyReused
+		}
+		return outcome.bid, outcome.err
+	}
+
+	var bid *model.Bid
+	err := s.checkBidder(userID)
+	if err == nil {
+		bid, err = s.placeBid(auctionID, userID, amount, quantity, now)
+	}
+	s.idempotency.remember(&bidOutcome{key: key, request: request, bid: bid, err: err}, now)
+	return bid, err
+}
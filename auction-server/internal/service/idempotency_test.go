@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestPlaceBidIdempotent_RetryReturnsOriginalBid(t *testing.T) {
+	svc := NewAuctionService(store.NewAuctionStore())
+	ctx := context.Background()
+
+	auction, err := svc.CreateAuction(ctx, CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	first, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(150), 1)
+	if err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	retry, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(150), 1)
+	if err != nil {
+		t.Fatalf("expected the retry to succeed like the original, got %v", err)
+	}
+	if retry.ID != first.ID || len(auction.Bids) != 1 {
+		t.Errorf("expected the original bid %s and no second bid, got %s and %d bids", first.ID, retry.ID, len(auction.Bids))
+	}
+
+	// The same key from another bidder is a different bid
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "bob", usd(200), 1); err != nil {
+		t.Errorf("expected bob's bid under the same key to be placed, got %v", err)
+	}
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(250), 1); !errors.Is(err, model.ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused for a different amount, got %v", err)
+	}
+	if _, err := svc.PlaceBidIdempotent(ctx, "   ", auction.ID, "alice", usd(250), 1); !errors.Is(err, model.ErrInvalidIdempotencyKey) {
+		t.Errorf("expected ErrInvalidIdempotencyKey for a blank key, got %v", err)
+	}
+}
+
+func TestPlaceBidIdempotent_RetryReturnsOriginalError(t *testing.T) {
+	svc := NewAuctionService(store.NewAuctionStore())
+	ctx := context.Background()
+
+	auction, err := svc.CreateAuction(ctx, CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(50), 1); !errors.Is(err, model.ErrBidTooLow) {
+		t.Fatalf("expected ErrBidTooLow, got %v", err)
+	}
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(50), 1); !errors.Is(err, model.ErrBidTooLow) {
+		t.Errorf("expected the retry to return the original ErrBidTooLow, got %v", err)
+	}
+	if len(auction.Bids) != 0 {
+		t.Errorf("expected no bids, got %d", len(auction.Bids))
+	}
+}
+
+func TestPlaceBidIdempotent_ConcurrentRetries(t *testing.T) {
+	svc := NewAuctionService(store.NewAuctionStore())
+	ctx := context.Background()
+
+	auction, err := svc.CreateAuction(ctx, CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	ids := make([]string, 10)
+	errs := make([]error, 10)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bid, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(150), 1)
+			if bid != nil {
+				ids[i] = bid.ID
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range ids {
+		if errs[i] != nil || ids[i] != ids[0] {
+			t.Errorf("attempt %d: expected bid %s, got %q (%v)", i, ids[0], ids[i], errs[i])
+		}
+	}
+	if len(auction.Bids) != 1 {
+		t.Errorf("expected a single bid, got %d", len(auction.Bids))
+	}
+}
+
+func TestPlaceBidIdempotent_ForgetsAfterWindow(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(store.NewAuctionStore(), WithClock(clk), WithIdempotencyWindow(5*time.Second))
+	ctx := context.Background()
+
+	auction, err := svc.CreateAuction(ctx, CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(150), 1); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+
+	clk.Advance(4 * time.Second)
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(150), 1); err != nil {
+		t.Errorf("expected the retry inside the window to succeed, got %v", err)
+	}
+
+	// Once forgotten, the key bids again and meets alice's own bid
+	clk.Advance(2 * time.Second)
+	if _, err := svc.PlaceBidIdempotent(ctx, "key-1", auction.ID, "alice", usd(150), 1); !errors.Is(err, model.ErrBidTooLow) {
+		t.Errorf("expected the key to be forgotten after the window, got %v", err)
+	}
+	if len(svc.idempotency.outcomes) != 1 || len(svc.idempotency.order) != 1 {
+		t.Errorf("expected only the latest outcome to be kept, got %d outcomes and %d queued", len(svc.idempotency.outcomes), len(svc.idempotency.order))
+	}
+}
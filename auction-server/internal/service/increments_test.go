@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+// tieredIncrements raises by 1 under 100, by 5 under 1000 and by 25 above
+func tieredIncrements() model.IncrementSchedule {
+	hundred, thousand := usd(100), usd(1000)
+	return model.IncrementSchedule{
+		{UpTo: &hundred, Increment: usd(1)},
+		{UpTo: &thousand, Increment: usd(5)},
+		{Increment: usd(25)},
+	}
+}
+
+func TestPlaceBid_TieredIncrements(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(95), Increments: tieredIncrements()})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	steps := []struct {
+		amount  model.Money
+		wantErr error
+		next    model.Money
+	}{
+		{model.MustParseMoney("95.50"), model.ErrBidTooLow, usd(96)},
+		{usd(99), nil, usd(100)},
+		{usd(100), nil, usd(105)},
+		{usd(104), model.ErrBidTooLow, usd(105)},
+		{usd(105), nil, usd(110)},
+		{usd(1000), nil, usd(1025)},
+		{usd(1010), model.ErrBidTooLow, usd(1025)},
+	}
+	for _, step := range steps {
+		_, err := svc.PlaceBid(context.Background(), auction.ID, "alice", step.amount)
+		if !errors.Is(err, step.wantErr) {
+			t.Errorf("bid of %s: expected %v, got %v", step.amount, step.wantErr, err)
+		}
+		if next := svc.GetNextBid(auction.ID); next != step.next {
+			t.Errorf("after bid of %s: expected next bid %s, got %s", step.amount, step.next, next)
+		}
+		if next := auction.NextBid(); next != step.next {
+			t.Errorf("after bid of %s: expected Auction.NextBid %s, got %s", step.amount, step.next, next)
+		}
+	}
+}
+
+func TestPlaceMaxBid_TieredIncrements(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Increments: tieredIncrements()})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(500)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+	if auction.CurrentBid != usd(105) {
+		t.Errorf("expected alice to open at 105.0, got %s", auction.CurrentBid)
+	}
+
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "bob", usd(300)); err != nil {
+		t.Fatalf("bob's maximum failed: %v", err)
+	}
+	if *auction.CurrentWinner != "alice" || auction.CurrentBid != usd(305) {
+		t.Errorf("expected alice to answer at 305.0, got %s at %s", *auction.CurrentWinner, auction.CurrentBid)
+	}
+}
+
+func TestCreateAuction_InvalidIncrements(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	hundred, fifty := usd(100), usd(50)
+	schedules := map[string]model.IncrementSchedule{
+		"empty":              {},
+		"zero increment":     {{Increment: usd(0)}},
+		"bands out of order": {{UpTo: &hundred, Increment: usd(1)}, {UpTo: &fifty, Increment: usd(5)}, {Increment: usd(10)}},
+		"bounded last band":  {{UpTo: &hundred, Increment: usd(1)}},
+		"open band too soon": {{Increment: usd(1)}, {UpTo: &hundred, Increment: usd(5)}},
+	}
+	for name, schedule := range schedules {
+		_, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Increments: schedule})
+		if !errors.Is(err, model.ErrInvalidIncrements) {
+			t.Errorf("%s: expected ErrInvalidIncrements, got %v", name, err)
+		}
+	}
+
+	drop, interval := usd(5), 10
+	_, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid:       usd(100),
+		Type:              model.AuctionTypeDutch,
+		PriceDropAmount:   &drop,
+		PriceDropInterval: &interval,
+		Increments:        tieredIncrements(),
+	})
+	if !errors.Is(err, model.ErrUnsupportedForType) {
+		t.Errorf("expected ErrUnsupportedForType for a Dutch auction with increments, got %v", err)
+	}
+}
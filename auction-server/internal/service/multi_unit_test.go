@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+// placeLotBids places bids on a 6-unit lot: alice 3 @ 150, bob 2 @ 130, carol 2 @ 120
+func placeLotBids(t *testing.T, svc *AuctionService, auctionID string) {
+	t.Helper()
+	for _, b := range []struct {
+		user     string
+		amount   model.Money
+		quantity int
+	}{{"alice", usd(150), 3}, {"bob", usd(130), 2}, {"carol", usd(120), 2}} {
+		if _, err := svc.PlaceBidForQuantity(context.Background(), auctionID, b.user, b.amount, b.quantity); err != nil {
+			t.Fatalf("%s's bid failed: %v", b.user, err)
+		}
+	}
+}
+
+func TestMultiUnit_UniformPricing(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, Quantity: 6})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if auction.Pricing != model.PricingUniform {
+		t.Errorf("expected UNIFORM pricing by default, got %s", auction.Pricing)
+	}
+
+	// Once the lot is fully subscribed, the lowest winning bid sets the price to beat
+	if _, err := svc.PlaceBidForQuantity(context.Background(), auction.ID, "alice", usd(150), 7); !errors.Is(err, model.ErrInvalidQuantity) {
+		t.Errorf("expected ErrInvalidQuantity for more units than the lot has, got %v", err)
+	}
+	placeLotBids(t, svc, auction.ID)
+	if auction.CurrentBid != usd(120) {
+		t.Errorf("expected clearing price 120.0, got %s", auction.CurrentBid)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "dave", usd(120)); !errors.Is(err, model.ErrBidTooLow) {
+		t.Errorf("expected ErrBidTooLow at the clearing price, got %v", err)
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	want := []model.Allocation{
+		{UserID: "alice", Quantity: 3, Price: usd(120)},
+		{UserID: "bob", Quantity: 2, Price: usd(120)},
+		{UserID: "carol", Quantity: 1, Price: usd(120)},
+	}
+	if len(auction.Allocations) != len(want) {
+		t.Fatalf("expected %d allocations, got %+v", len(want), auction.Allocations)
+	}
+	for i, w := range want {
+		got := auction.Allocations[i]
+		if got.UserID != w.UserID || got.Quantity != w.Quantity || got.Price != w.Price {
+			t.Errorf("allocation %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+}
+
+func TestMultiUnit_PayAsBidPartialFill(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid: usd(100),
+		Duration:    30,
+		Quantity:    6,
+		Pricing:     model.PricingPayAsBid,
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	placeLotBids(t, svc, auction.ID)
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	if len(auction.Allocations) != 3 {
+		t.Fatalf("expected 3 allocations, got %+v", auction.Allocations)
+	}
+	if last := auction.Allocations[2]; last.UserID != "carol" || last.Quantity != 1 || last.Price != usd(120) {
+		t.Errorf("expected carol to get 1 unit at her bid of 120.0, got %+v", last)
+	}
+	if first := auction.Allocations[0]; first.Price != usd(150) {
+		t.Errorf("expected alice to pay her bid of 150.0, got %s", first.Price)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// PlaceMaxBid records a hidden maximum for the user and lets the server bid on
+// their behalf, one increment at a time, against competing bids and proxies.
+// It returns the user's latest bid, which may already have been outbid by a
+// higher proxy, or the highest bid if the maximum is already covered.
+func (s *AuctionService) PlaceMaxBid(ctx context.Context, auctionID string, userID string, maxAmount model.Money) (*model.Bid, error) {
+	if err := s.checkBidder(userID); err != nil {
+		return nil, err
+	}
+
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	now := s.clock.Now()
+	auction, err := s.biddableAuction(auctionID, now)
+	if err != nil {
+		return nil, err
+	}
+	if auction.Type == model.AuctionTypeDutch || auction.IsSealed() || auction.IsMultiUnit() {
+		return nil, model.ErrUnsupportedForType
+	}
+
+	if maxAmount, err = maxAmount.In(auction.Currency()); err != nil {
+		return nil, err
+	}
+	if err := s.validationRule.ValidateBidLimit(maxAmount); err != nil {
+		return nil, err
+	}
+
+	// A maximum may only ever be raised. The leader just has to stay above their
+	// own bid; anyone else has to cover the next valid bid.
+	isLeader := auction.CurrentWinner != nil && *auction.CurrentWinner == userID
+	if existing := auction.ProxyFor(userID); existing != nil && maxAmount.Cmp(existing.MaxAmount) <= 0 {
+		return nil, model.NewBidTooLowError(auction.CurrentBid, maxAmount)
+	}
+	if (isLeader && maxAmount.Cmp(auction.CurrentBid) <= 0) ||
+		(!isLeader && maxAmount.Cmp(s.validationRule.CalculateNextMinimumBid(auction.CurrentBid, auction.Increments)) < 0) {
+		return nil, model.NewBidTooLowError(auction.CurrentBid, maxAmount)
+	}
+	// Ties go to the earlier proxy, so matching another maximum could never bid
+	for _, proxy := range auction.ProxyBids {
+		if proxy.UserID != userID && proxy.MaxAmount.Cmp(maxAmount) == 0 {
+			return nil, model.NewBidTooLowError(auction.CurrentBid, maxAmount)
+		}
+	}
+
+	// The whole maximum is held, since the server may bid all of it
+	undo, err := s.holdFunds(auction, userID, maxAmount)
+	if err != nil {
+		return nil, err
+	}
+	proxy := model.ProxyBid{UserID: userID, MaxAmount: maxAmount, PlacedAt: now}
+	if err := s.commit(eventlog.NewMaxBidPlacedRecord(auctionID, proxy), func() error {
+		return s.store.UpdateAuction(auctionID, func(a *model.Auction) error {
+			a.SetProxy(proxy)
+			return nil
+		})
+	}); err != nil {
+		undo()
+		return nil, err
+	}
+
+	bidsBefore := len(auction.Bids)
+	if err := s.resolveProxyBids(auction, now); err != nil {
+		return nil, err
+	}
+
+	// Report the bid the proxy placed for the user, if any
+	for i := len(auction.Bids) - 1; i >= bidsBefore; i-- {
+		if auction.Bids[i].IsPlacedBy(userID) {
+			bid := auction.Bids[i]
+			return &bid, nil
+		}
+	}
+	// Raising the maximum while leading doesn't need a new bid, and a maximum
+	// that a higher proxy already covers stays stored without bidding
+	return auction.HighestBid(), nil
+}
+
+// resolveProxyBids lets proxies bid against the current leader until no proxy
+// can outbid it. Every automatic bid is accepted and broadcast like a manual one.
+// Callers must hold timerMutex.
+func (s *AuctionService) resolveProxyBids(auction *model.Auction, now time.Time) error {
+	for {
+		leader := ""
+		if auction.CurrentWinner != nil {
+			leader = *auction.CurrentWinner
+		}
+
+		nextMinimum := s.validationRule.CalculateNextMinimumBid(auction.CurrentBid, auction.Increments)
+		challenger := strongestProxy(auction, leader, nextMinimum)
+		if challenger == nil {
+			return nil
+		}
+
+		// How far the leader is willing to go
+		leaderMax := auction.CurrentBid
+		if proxy := auction.ProxyFor(leader); proxy != nil && proxy.MaxAmount.Cmp(leaderMax) > 0 {
+			leaderMax = proxy.MaxAmount
+		}
+
+		if challenger.MaxAmount.Cmp(leaderMax) > 0 {
+			// The challenger takes the lead by a single increment over the leader's maximum
+			amount := model.MinMoney(challenger.MaxAmount, s.validationRule.CalculateNextMinimumBid(leaderMax, auction.Increments))
+			amount = model.MaxMoney(amount, nextMinimum)
+			// A maximum that covers the reserve bids straight up to it
+			if auction.ReservePrice != nil && amount.Cmp(*auction.ReservePrice) < 0 && challenger.MaxAmount.Cmp(*auction.ReservePrice) >= 0 {
+				amount = *auction.ReservePrice
+			}
+			if _, err := s.acceptBid(auction, challenger.UserID, amount, 1, true, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The leader's proxy wins: the challenger bids its full maximum and the
+		// leader answers with one increment more. On a tie the earlier proxy keeps
+		// the lead at that amount.
+		if challenger.MaxAmount.Cmp(leaderMax) < 0 {
+			if _, err := s.acceptBid(auction, challenger.UserID, challenger.MaxAmount, 1, true, now); err != nil {
+				return err
+			}
+		}
+		amount := model.MinMoney(leaderMax, s.validationRule.CalculateNextMinimumBid(challenger.MaxAmount, auction.Increments))
+		if _, err := s.acceptBid(auction, leader, amount, 1, true, now); err != nil {
+			return err
+		}
+	}
+}
+
+// strongestProxy returns the highest proxy, excluding the leader's, that can
+// still afford nextMinimum. Ties go to the proxy placed first.
+func strongestProxy(auction *model.Auction, leader string, nextMinimum model.Money) *model.ProxyBid {
+	var best *model.ProxyBid
+	for i := range auction.ProxyBids {
+		proxy := &auction.ProxyBids[i]
+		if proxy.UserID == leader || proxy.MaxAmount.Cmp(nextMinimum) < 0 {
+			continue
+		}
+		if best == nil || proxy.MaxAmount.Cmp(best.MaxAmount) > 0 ||
+			(proxy.MaxAmount.Cmp(best.MaxAmount) == 0 && proxy.PlacedAt.Before(best.PlacedAt)) {
+			best = proxy
+		}
+	}
+	return best
+}
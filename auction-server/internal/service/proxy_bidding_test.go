@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestPlaceMaxBid_BidsOneIncrement(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	bid, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(200))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if bid.Amount != usd(101) || !bid.Automatic {
+		t.Errorf("expected automatic bid of 101.0, got %+v", bid)
+	}
+	if auction.CurrentBid != usd(101) {
+		t.Errorf("expected current bid 101.0, got %s", auction.CurrentBid)
+	}
+}
+
+func TestPlaceMaxBid_CompetingProxies(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	events := svc.Subscribe("test", auction.ID)
+
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(200)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+
+	bid, err := svc.PlaceMaxBid(context.Background(), auction.ID, "bob", usd(150))
+	if err != nil {
+		t.Fatalf("bob's maximum failed: %v", err)
+	}
+	if bid.UserID != "bob" || bid.Amount != usd(150) {
+		t.Errorf("expected bob's bid of 150.0, got %+v", bid)
+	}
+
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "alice" {
+		t.Errorf("expected alice to lead, got %v", auction.CurrentWinner)
+	}
+	if auction.CurrentBid != usd(151) {
+		t.Errorf("expected current bid 151.0, got %s", auction.CurrentBid)
+	}
+
+	// alice 101, bob 150, alice 151
+	if got := len(events); got != 3 {
+		t.Errorf("expected 3 BID_PLACED events, got %d", got)
+	}
+}
+
+func TestPlaceMaxBid_TieGoesToEarlierProxy(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(200)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+
+	_, err = svc.PlaceMaxBid(context.Background(), auction.ID, "bob", usd(200))
+	if !errors.Is(err, model.ErrBidTooLow) {
+		t.Errorf("expected ErrBidTooLow, got %v", err)
+	}
+
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "alice" || auction.CurrentBid != usd(101) {
+		t.Errorf("expected alice to lead at 101.0, got %v at %s", auction.CurrentWinner, auction.CurrentBid)
+	}
+}
+
+func TestPlaceMaxBid_RejectedTieLeavesNoTrace(t *testing.T) {
+	l, err := eventlog.Open(filepath.Join(t.TempDir(), "events.log"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer l.Close()
+	users := NewUserService(store.NewMemoryRepository(), WithAutoVerify(), WithStartingCredit(usd(1000)))
+	for _, id := range []string{"alice", "bob"} {
+		if _, err := users.RegisterBidder(context.Background(), id, id); err != nil {
+			t.Fatalf("registering %s failed: %v", id, err)
+		}
+	}
+	svc := NewAuctionService(store.NewAuctionStore(), WithEventLog(l), WithBidderRegistry(users), WithCreditLedger(users))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(200)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "bob", usd(200)); !errors.Is(err, model.ErrBidTooLow) {
+		t.Fatalf("expected ErrBidTooLow, got %v", err)
+	}
+
+	if proxy := auction.ProxyFor("bob"); proxy != nil {
+		t.Errorf("expected bob to have no proxy, got %+v", proxy)
+	}
+	if got := users.GetUser("bob").HoldOn(auction.ID); got.IsPositive() {
+		t.Errorf("expected bob to have no hold, got %s", got)
+	}
+	records, err := l.Records()
+	if err != nil {
+		t.Fatalf("reading records failed: %v", err)
+	}
+	for _, rec := range records {
+		if rec.Type == eventlog.RecordMaxBidPlaced && rec.ProxyBid.UserID == "bob" {
+			t.Errorf("expected no MAX_BID_PLACED record for bob, got %+v", rec)
+		}
+	}
+}
+
+func TestPlaceBid_ProxyResponds(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if _, err := svc.PlaceMaxBid(context.Background(), auction.ID, "alice", usd(200)); err != nil {
+		t.Fatalf("alice's maximum failed: %v", err)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(120)); err != nil {
+		t.Fatalf("bob's bid failed: %v", err)
+	}
+	if *auction.CurrentWinner != "alice" || auction.CurrentBid != usd(121) {
+		t.Errorf("expected alice to answer at 121.0, got %s at %s", *auction.CurrentWinner, auction.CurrentBid)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(250)); err != nil {
+		t.Fatalf("bob's bid failed: %v", err)
+	}
+	if *auction.CurrentWinner != "bob" || auction.CurrentBid != usd(250) {
+		t.Errorf("expected bob to lead at 250.0, got %s at %s", *auction.CurrentWinner, auction.CurrentBid)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// scheduleStart waits until a pending auction's start time, opens it and then
+// runs its countdown
+func (s *AuctionService) scheduleStart(auctionID string) {
+	for {
+		auction := s.store.GetAuction(auctionID)
+		if auction == nil || auction.Status != model.AuctionStatusPending {
+			return
+		}
+
+		if wait := auction.StartTime.Sub(s.clock.Now()); wait > 0 {
+			<-s.clock.After(wait)
+			continue
+		}
+
+		if s.openAuction(auction) {
+			s.startCountdown(auctionID)
+			return
+		}
+		// Recording the start failed; try again shortly
+		<-s.clock.After(time.Second)
+	}
+}
+
+// openAuction promotes a pending auction to ACTIVE and broadcasts
+// AUCTION_STARTED. It returns false if the auction could not be opened.
+func (s *AuctionService) openAuction(auction *model.Auction) bool {
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	if auction.Status != model.AuctionStatusPending {
+		return false
+	}
+
+	if err := s.commit(eventlog.NewAuctionStartedRecord(auction.ID, s.clock.Now()), func() error {
+		return s.store.UpdateAuction(auction.ID, func(a *model.Auction) error {
+			a.Status = model.AuctionStatusActive
+			return nil
+		})
+	}); err != nil {
+		log.Printf("failed to start auction %s: %v", auction.ID, err)
+		return false
+	}
+
+	s.store.Broadcast(model.NewAuctionStartedEvent(auction))
+	return true
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestScheduledAuction_StartsPending(t *testing.T) {
+	st := store.NewAuctionStore()
+	svc := NewAuctionService(st)
+
+	start := time.Now().Add(time.Hour)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, StartTime: &start})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	if auction.Status != model.AuctionStatusPending {
+		t.Errorf("expected status PENDING, got %s", auction.Status)
+	}
+	if !auction.EndTime.Equal(start.Add(30 * time.Second)) {
+		t.Errorf("expected end time 30s after the start, got %v", auction.EndTime)
+	}
+
+	pending := model.AuctionStatusPending
+	if listed := svc.ListAuctions(&pending); len(listed) != 1 || listed[0].ID != auction.ID {
+		t.Errorf("expected the auction to be listed as pending, got %v", listed)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); !errors.Is(err, model.ErrAuctionNotStarted) {
+		t.Errorf("expected ErrAuctionNotStarted, got %v", err)
+	}
+}
+
+func TestScheduledAuction_OpensAtStartTime(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	start := clk.Now().Add(time.Hour)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, StartTime: &start})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	events := svc.Subscribe("test", auction.ID)
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	if event := <-events; event.Type != model.EventAuctionStarted {
+		t.Errorf("expected AUCTION_STARTED, got %s", event.Type)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Errorf("expected bid on the opened auction to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// acceptSealedBid stores a hidden bid on a sealed auction. Other bidders only
+// learn that a bid was placed; the winner and price are settled when the
+// auction closes. Every bidder's credit stays held until then. Callers must
+// hold timerMutex.
+func (s *AuctionService) acceptSealedBid(auction *model.Auction, userID string, amount model.Money, now time.Time) (*model.Bid, error) {
+	if err := s.validationRule.ValidateBidLimit(amount); err != nil {
+		return nil, err
+	}
+	if amount.Cmp(auction.StartingBid) < 0 {
+		return nil, model.NewBidTooLowError(auction.StartingBid, amount)
+	}
+	if auction.SealedBidFor(userID) != nil && !auction.ReplaceableBids {
+		return nil, model.ErrAlreadyBid
+	}
+
+	undo, err := s.holdFunds(auction, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	bid := &model.Bid{
+		ID:        fmt.Sprintf("bid-%d", s.store.GetNextBidID()),
+		AuctionID: auction.ID,
+		UserID:    userID,
+		Quantity:  1,
+		Amount:    amount,
+		Sealed:    true,
+		Timestamp: now,
+	}
+	if err := s.commit(eventlog.NewSealedBidPlacedRecord(bid), func() error {
+		return s.store.UpdateAuction(auction.ID, func(a *model.Auction) error {
+			a.SetSealedBid(*bid)
+			return nil
+		})
+	}); err != nil {
+		undo()
+		return nil, err
+	}
+
+	s.store.Broadcast(model.NewBidPlacedEvent(auction, bid.Masked()))
+
+	return bid, nil
+}
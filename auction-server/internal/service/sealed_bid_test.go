@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestSealedBid_HidesBidsUntilClose(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid: usd(100),
+		Duration:    30,
+		Type:        model.AuctionTypeSealedFirstPrice,
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	events := svc.Subscribe("test", auction.ID)
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("alice's bid failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "bob", usd(120)); err != nil {
+		t.Fatalf("bob's bid failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(200)); !errors.Is(err, model.ErrAlreadyBid) {
+		t.Errorf("expected ErrAlreadyBid, got %v", err)
+	}
+
+	event := <-events
+	if event.Bid.Amount.IsPositive() || !event.Bid.Sealed {
+		t.Errorf("expected a masked bid, got %+v", event.Bid)
+	}
+	if auction.CurrentBid != usd(100) || auction.CurrentWinner != nil {
+		t.Errorf("expected nothing revealed, got %v at %s", auction.CurrentWinner, auction.CurrentBid)
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "alice" || auction.CurrentBid != usd(150) {
+		t.Errorf("expected alice to win at 150.0, got %v at %s", auction.CurrentWinner, auction.CurrentBid)
+	}
+	if highest := auction.HighestBid(); highest == nil || highest.UserID != "alice" || highest.Amount != usd(150) {
+		t.Errorf("expected alice's sealed bid to be the highest, got %+v", highest)
+	}
+}
+
+func TestSealedBid_VickreyPaysSecondPrice(t *testing.T) {
+	st := store.NewAuctionStore()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(st, WithClock(clk))
+
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid:     usd(100),
+		Duration:        30,
+		Type:            model.AuctionTypeVickrey,
+		ReplaceableBids: true,
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	for _, b := range []struct {
+		user   string
+		amount model.Money
+	}{{"alice", usd(300)}, {"bob", usd(180)}, {"carol", usd(120)}, {"alice", usd(250)}} {
+		if _, err := svc.PlaceBid(context.Background(), auction.ID, b.user, b.amount); err != nil {
+			t.Fatalf("%s's bid failed: %v", b.user, err)
+		}
+	}
+
+	clk.Advance(31 * time.Second)
+	svc.endAuction(auction)
+
+	if auction.Status != model.AuctionStatusEnded {
+		t.Errorf("expected status ENDED, got %s", auction.Status)
+	}
+	if auction.CurrentWinner == nil || *auction.CurrentWinner != "alice" || auction.CurrentBid != usd(180) {
+		t.Errorf("expected alice to win at bob's 180.0, got %v at %s", auction.CurrentWinner, auction.CurrentBid)
+	}
+}
+
+func TestSealedBid_ReplayKeepsBidIDsUnique(t *testing.T) {
+	l, err := eventlog.Open(filepath.Join(t.TempDir(), "events.log"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer l.Close()
+	clk := clock.NewFake(time.Now())
+	svc := NewAuctionService(store.NewAuctionStore(), WithClock(clk), WithEventLog(l))
+
+	sealed, err := svc.CreateAuction(context.Background(), CreateAuctionParams{
+		StartingBid: usd(100),
+		Duration:    30,
+		Type:        model.AuctionTypeSealedFirstPrice,
+	})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	placed := make(map[string]bool)
+	for _, user := range []string{"alice", "bob"} {
+		bid, err := svc.PlaceBid(context.Background(), sealed.ID, user, usd(150))
+		if err != nil {
+			t.Fatalf("%s's bid failed: %v", user, err)
+		}
+		placed[bid.ID] = true
+	}
+
+	// Restart on the memory backend, as main does
+	records, err := l.Records()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	auctions, err := eventlog.Replay(records)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	repo := store.NewMemoryRepository()
+	repo.Restore(auctions)
+	restarted := NewAuctionService(store.NewAuctionStoreWithRepository(repo), WithClock(clk))
+	if got := restarted.GetAuction(sealed.ID); got == nil || len(got.SealedBids) != 2 {
+		t.Fatalf("expected the sealed auction back with 2 bids, got %+v", got)
+	}
+
+	english, err := restarted.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	bid, err := restarted.PlaceBid(context.Background(), english.ID, "carol", usd(150))
+	if err != nil {
+		t.Fatalf("carol's bid failed: %v", err)
+	}
+	if placed[bid.ID] {
+		t.Errorf("expected a fresh bid ID after the restart, got %s again", bid.ID)
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+// UserService handles bidder registration, verification and suspension
+type UserService struct {
+	users          store.UserRepository
+	validationRule *model.ValidationRules
+	clock          clock.Clock
+	autoVerify     bool
+	startingCredit model.Money
+}
+
+// UserOption configures optional UserService behaviour
+type UserOption func(*UserService)
+
+// WithAutoVerify marks bidders verified as soon as they register, e.g. for local testing
+func WithAutoVerify() UserOption {
+	return func(s *UserService) {
+		s.autoVerify = true
+	}
+}
+
+// WithStartingCredit gives every new bidder a deposit, e.g. for local testing
+func WithStartingCredit(amount model.Money) UserOption {
+	return func(s *UserService) {
+		s.startingCredit = amount
+	}
+}
+
+// WithUserClock makes the service tell time by the given clock instead of the system clock
+func WithUserClock(c clock.Clock) UserOption {
+	return func(s *UserService) {
+		s.clock = c
+	}
+}
+
+// NewUserService creates a new user service
+func NewUserService(users store.UserRepository, opts ...UserOption) *UserService {
+	s := &UserService{
+		users:          users,
+		validationRule: model.DefaultValidationRules(),
+		clock:          clock.Real,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RegisterBidder creates the profile of a new bidder. They can't bid until
+// they are verified.
+func (s *UserService) RegisterBidder(ctx context.Context, userID string, displayName string) (*model.User, error) {
+	if err := s.validationRule.ValidateDisplayName(displayName); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	user := &model.User{
+		ID:           userID,
+		DisplayName:  strings.TrimSpace(displayName),
+		Verification: model.VerificationPending,
+		RegisteredAt: now,
+	}
+	if s.autoVerify {
+		user.Verification = model.VerificationVerified
+		user.VerifiedAt = &now
+	}
+	if s.startingCredit.IsPositive() {
+		if err := user.Deposit(s.startingCredit); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.users.AddUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUser returns the user with the given ID, or nil if they never registered
+func (s *UserService) GetUser(id string) *model.User {
+	return s.users.GetUser(id)
+}
+
+// ListUsers returns all registered users
+func (s *UserService) ListUsers() []*model.User {
+	return s.users.ListUsers()
+}
+
+// VerifyBidder records that the bidder's identity has been checked
+func (s *UserService) VerifyBidder(ctx context.Context, userID string) (*model.User, error) {
+	now := s.clock.Now()
+	return s.update(userID, func(u *model.User) {
+		u.Verification = model.VerificationVerified
+		u.VerifiedAt = &now
+	})
+}
+
+// RejectBidder records that the bidder's identity check failed
+func (s *UserService) RejectBidder(ctx context.Context, userID string) (*model.User, error) {
+	return s.update(userID, func(u *model.User) {
+		u.Verification = model.VerificationRejected
+		u.VerifiedAt = nil
+	})
+}
+
+// SuspendBidder stops the bidder from placing bids until reinstated
+func (s *UserService) SuspendBidder(ctx context.Context, userID string, reason string) (*model.User, error) {
+	return s.update(userID, func(u *model.User) {
+		u.Suspended = true
+		u.SuspensionReason = strings.TrimSpace(reason)
+	})
+}
+
+// ReinstateBidder lifts a suspension
+func (s *UserService) ReinstateBidder(ctx context.Context, userID string) (*model.User, error) {
+	return s.update(userID, func(u *model.User) {
+		u.Suspended = false
+		u.SuspensionReason = ""
+	})
+}
+
+// CheckEligible returns why the user may not bid: they never registered, aren't
+// verified or are suspended. It returns nil if they may.
+func (s *UserService) CheckEligible(userID string) error {
+	user := s.users.GetUser(userID)
+	if user == nil {
+		return model.NewUnknownBidderError(userID)
+	}
+	return user.Eligibility()
+}
+
+// DepositCredit adds to a bidder's credit
+func (s *UserService) DepositCredit(ctx context.Context, userID string, amount model.Money) (*model.User, error) {
+	if !amount.IsPositive() {
+		return nil, model.ErrInvalidMoney
+	}
+	if amount.Currency == "" {
+		amount.Currency = model.DefaultCurrency
+	}
+	if err := s.users.UpdateUser(userID, func(u *model.User) error {
+		return u.Deposit(amount)
+	}); err != nil {
+		return nil, err
+	}
+	return s.users.GetUser(userID), nil
+}
+
+// Hold commits amount of the user's credit to an auction in place of their
+// previous hold on it, which it returns. A zero amount releases the hold and a
+// negative one is an ErrInvalidMoney. It fails with a *model.CreditError if the
+// credit that isn't held elsewhere doesn't cover the amount.
+func (s *UserService) Hold(userID string, auctionID string, amount model.Money) (model.Money, error) {
+	if amount.Cents < 0 {
+		return model.Money{}, fmt.Errorf("%w: can't hold %s", model.ErrInvalidMoney, amount)
+	}
+	var previous model.Money
+	err := s.users.UpdateUser(userID, func(u *model.User) error {
+		previous = u.HoldOn(auctionID)
+		if amount.IsPositive() {
+			available := u.Available(amount.Currency)
+			if previous.Currency == amount.Currency {
+				var err error
+				if available, err = available.Add(previous); err != nil {
+					return err
+				}
+			}
+			if available.Cmp(amount) < 0 {
+				return &model.CreditError{UserID: userID, Required: amount, Available: available}
+			}
+		}
+		u.SetHold(auctionID, amount, s.clock.Now())
+		return nil
+	})
+	return previous, err
+}
+
+// Release drops the user's hold on an auction, if they have one
+func (s *UserService) Release(userID string, auctionID string) error {
+	if user := s.users.GetUser(userID); user == nil || !user.HoldOn(auctionID).IsPositive() {
+		return nil
+	}
+	return s.users.UpdateUser(userID, func(u *model.User) error {
+		u.SetHold(auctionID, model.Money{}, s.clock.Now())
+		return nil
+	})
+}
+
+// Settle charges the winners of a closed auction, recording a settlement for
+// each, and releases every other hold on it
+func (s *UserService) Settle(auctionID string, charges map[string]model.Money, at time.Time) error {
+	var errs []error
+	// Every user is checked; holds aren't indexed by auction
+	for _, user := range s.users.ListUsers() {
+		charge, won := charges[user.ID]
+		if !won && !user.HoldOn(auctionID).IsPositive() {
+			continue
+		}
+		errs = append(errs, s.users.UpdateUser(user.ID, func(u *model.User) error {
+			if won {
+				u.Settle(auctionID, charge, at)
+			} else {
+				u.SetHold(auctionID, model.Money{}, at)
+			}
+			return nil
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// update applies change to the user under the store's lock and returns the updated copy
+func (s *UserService) update(userID string, change func(*model.User)) (*model.User, error) {
+	if err := s.users.UpdateUser(userID, func(u *model.User) error {
+		change(u)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return s.users.GetUser(userID), nil
+}
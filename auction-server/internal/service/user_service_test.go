@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func TestRegisterBidder(t *testing.T) {
+	users := NewUserService(store.NewMemoryRepository())
+
+	user, err := users.RegisterBidder(context.Background(), "alice", "  Alice  ")
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	if user.DisplayName != "Alice" || user.Verification != model.VerificationPending || user.CanBid() {
+		t.Errorf("expected a pending bidder named Alice, got %+v", user)
+	}
+
+	if _, err := users.RegisterBidder(context.Background(), "alice", "Alice again"); !errors.Is(err, model.ErrAlreadyRegistered) {
+		t.Errorf("expected ErrAlreadyRegistered, got %v", err)
+	}
+	if _, err := users.RegisterBidder(context.Background(), "bob", "   "); !errors.Is(err, model.ErrInvalidDisplayName) {
+		t.Errorf("expected ErrInvalidDisplayName, got %v", err)
+	}
+	if _, err := users.VerifyBidder(context.Background(), "nobody"); !errors.Is(err, model.ErrUnknownBidder) {
+		t.Errorf("expected ErrUnknownBidder, got %v", err)
+	}
+
+	autoVerified := NewUserService(store.NewMemoryRepository(), WithAutoVerify())
+	user, err = autoVerified.RegisterBidder(context.Background(), "carol", "Carol")
+	if err != nil || !user.CanBid() || user.VerifiedAt == nil {
+		t.Errorf("expected carol to be verified on registration, got %+v (%v)", user, err)
+	}
+}
+
+func TestPlaceBid_BidderEligibility(t *testing.T) {
+	users := NewUserService(store.NewMemoryRepository())
+	svc := NewAuctionService(store.NewAuctionStore(), WithBidderRegistry(users))
+	ctx := context.Background()
+
+	buyNow := usd(500)
+	auction, err := svc.CreateAuction(ctx, CreateAuctionParams{StartingBid: usd(100), BuyNowPrice: &buyNow})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	attempts := func() map[string]error {
+		_, bidErr := svc.PlaceBid(ctx, auction.ID, "alice", usd(150))
+		_, maxErr := svc.PlaceMaxBid(ctx, auction.ID, "alice", usd(150))
+		_, buyErr := svc.BuyNow(ctx, auction.ID, "alice")
+		return map[string]error{"PlaceBid": bidErr, "PlaceMaxBid": maxErr, "BuyNow": buyErr}
+	}
+	expectAll := func(stage string, want error) {
+		t.Helper()
+		for name, err := range attempts() {
+			if !errors.Is(err, want) {
+				t.Errorf("%s, %s: expected %v, got %v", stage, name, want, err)
+			}
+		}
+	}
+
+	expectAll("unregistered", model.ErrUnknownBidder)
+
+	users.RegisterBidder(ctx, "alice", "Alice")
+	expectAll("unverified", model.ErrBidderNotVerified)
+
+	users.VerifyBidder(ctx, "alice")
+	users.SuspendBidder(ctx, "alice", "unpaid invoice")
+	_, err = svc.PlaceBid(ctx, auction.ID, "alice", usd(150))
+	var bidderErr *model.BidderError
+	if !errors.As(err, &bidderErr) || bidderErr.Err != model.ErrBidderSuspended || bidderErr.Reason != "unpaid invoice" || bidderErr.UserID != "alice" {
+		t.Errorf("expected a suspension BidderError with its reason, got %#v", err)
+	}
+	if len(auction.Bids) != 0 {
+		t.Errorf("expected no bids from an ineligible bidder, got %d", len(auction.Bids))
+	}
+
+	users.ReinstateBidder(ctx, "alice")
+	if _, err := svc.PlaceBid(ctx, auction.ID, "alice", usd(150)); err != nil {
+		t.Errorf("expected a reinstated, verified bidder to bid, got %v", err)
+	}
+}
+
+func TestHold_RejectsNegativeAmounts(t *testing.T) {
+	users := NewUserService(store.NewMemoryRepository(), WithAutoVerify())
+	if _, err := users.RegisterBidder(context.Background(), "alice", "Alice"); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	if _, err := users.DepositCredit(context.Background(), "alice", usd(100)); err != nil {
+		t.Fatalf("deposit failed: %v", err)
+	}
+	if _, err := users.Hold("alice", "auction-1", usd(80)); err != nil {
+		t.Fatalf("hold failed: %v", err)
+	}
+
+	if _, err := users.Hold("alice", "auction-2", usd(-50)); !errors.Is(err, model.ErrInvalidMoney) {
+		t.Errorf("expected ErrInvalidMoney for a negative hold, got %v", err)
+	}
+	if got := users.GetUser("alice").Available("USD"); got != usd(20) {
+		t.Errorf("expected the negative hold to leave 20.0 available, got %s", got)
+	}
+
+	previous, err := users.Hold("alice", "auction-1", model.Money{})
+	if err != nil || previous != usd(80) {
+		t.Fatalf("expected a zero hold to release 80.0, got %s (%v)", previous, err)
+	}
+	if alice := users.GetUser("alice"); len(alice.Holds) != 0 || alice.Available("USD") != usd(100) {
+		t.Errorf("expected no holds and 100.0 available, got %+v", alice)
+	}
+}
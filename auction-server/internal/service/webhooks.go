@@ -0,0 +1,440 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+const (
+	// DefaultWebhookAttempts is how many times a delivery is tried before it
+	// goes to the dead-letter list
+	DefaultWebhookAttempts = 8
+	// DefaultWebhookBackoff is the wait before the first retry; each retry
+	// after that waits twice as long, up to maxWebhookBackoff
+	DefaultWebhookBackoff = 5 * time.Second
+	maxWebhookBackoff     = time.Hour
+	// DefaultWebhookWorkers is how many webhooks are posted to at once
+	DefaultWebhookWorkers = 8
+	// webhookTimeout bounds a single delivery attempt
+	webhookTimeout = 10 * time.Second
+	// webhookIdleWait is how long the queue sleeps with nothing scheduled;
+	// new deliveries wake it sooner
+	webhookIdleWait = time.Minute
+	// maxWebhookResponse is how much of a receiver's response is read, so the
+	// connection can be reused
+	maxWebhookResponse = 64 << 10
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// timestamp, a dot and the body, keyed with the webhook's secret
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookService registers webhooks and delivers auction events to them. Events
+// are handed over as they are broadcast, queued in the repository and posted by
+// Run, which retries failures with exponential backoff until they run out of
+// attempts and go to the dead-letter list. Each webhook gets its events in the
+// order they happened; different webhooks are posted to concurrently.
+type WebhookService struct {
+	webhooks       store.WebhookRepository
+	validationRule *model.ValidationRules
+	clock          clock.Clock
+	client         *http.Client
+	attempts       int
+	backoff        time.Duration
+	workers        int
+	wake           chan struct{}
+
+	// Broadcast events waiting to be queued, oldest first
+	handoffMu sync.Mutex
+	handoff   []*model.AuctionEvent
+	handedOff chan struct{}
+	flushMu   sync.Mutex // keeps events queued in the order they were broadcast
+}
+
+// WebhookOption configures optional WebhookService behaviour
+type WebhookOption func(*WebhookService)
+
+// WithWebhookClock makes the service tell time by the given clock instead of the system clock
+func WithWebhookClock(c clock.Clock) WebhookOption {
+	return func(s *WebhookService) {
+		s.clock = c
+	}
+}
+
+// WithWebhookClient posts deliveries with the given HTTP client
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(s *WebhookService) {
+		s.client = client
+	}
+}
+
+// WithWebhookRetries sets how many times a delivery is tried and the wait
+// before its first retry
+func WithWebhookRetries(attempts int, backoff time.Duration) WebhookOption {
+	return func(s *WebhookService) {
+		s.attempts = attempts
+		s.backoff = backoff
+	}
+}
+
+// WithWebhookWorkers sets how many webhooks are posted to at once
+func WithWebhookWorkers(workers int) WebhookOption {
+	return func(s *WebhookService) {
+		s.workers = max(1, workers)
+	}
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhooks store.WebhookRepository, opts ...WebhookOption) *WebhookService {
+	s := &WebhookService{
+		webhooks:       webhooks,
+		validationRule: model.DefaultValidationRules(),
+		clock:          clock.Real,
+		client:         &http.Client{Timeout: webhookTimeout},
+		attempts:       DefaultWebhookAttempts,
+		backoff:        DefaultWebhookBackoff,
+		workers:        DefaultWebhookWorkers,
+		wake:           make(chan struct{}, 1),
+		handedOff:      make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RegisterWebhook registers a URL to receive events of the given types, signed
+// with secret
+func (s *WebhookService) RegisterWebhook(ctx context.Context, createdBy string, url string, eventTypes []model.AuctionEventType, secret string) (*model.Webhook, error) {
+	if err := s.validationRule.ValidateWebhook(url, eventTypes, secret); err != nil {
+		return nil, err
+	}
+
+	webhook := &model.Webhook{
+		ID:         fmt.Sprintf("webhook-%d", s.webhooks.GetNextWebhookID()),
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedBy:  createdBy,
+		CreatedAt:  s.clock.Now(),
+	}
+	if err := s.webhooks.AddWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook; its queued and dead deliveries go with it
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	webhook := s.webhooks.GetWebhook(id)
+	if webhook == nil {
+		return nil, model.ErrWebhookNotFound
+	}
+	if err := s.webhooks.DeleteWebhook(id); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns all webhooks in registration order
+func (s *WebhookService) ListWebhooks() []*model.Webhook {
+	return s.webhooks.ListWebhooks()
+}
+
+// ListDeliveries returns the queued deliveries with the given status, e.g. the
+// dead-letter list for model.DeliveryDead
+func (s *WebhookService) ListDeliveries(status model.DeliveryStatus) []*model.WebhookDelivery {
+	return s.webhooks.ListDeliveries(status)
+}
+
+// RetryDelivery puts a delivery back in the queue with a fresh set of attempts
+func (s *WebhookService) RetryDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	err := s.webhooks.UpdateDelivery(id, func(delivery *model.WebhookDelivery) error {
+		delivery.Status = model.DeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = s.clock.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+	return s.webhooks.GetDelivery(id), nil
+}
+
+// Enqueue hands an event over to be queued for every webhook registered for its
+// type. It is an AuctionStore listener and runs under the store's lock, so it
+// leaves the writing to the repository to Run, and the posting too.
+func (s *WebhookService) Enqueue(event *model.AuctionEvent) {
+	s.handoffMu.Lock()
+	s.handoff = append(s.handoff, event)
+	s.handoffMu.Unlock()
+	signal(s.handedOff)
+}
+
+// Flush queues the events handed over so far
+func (s *WebhookService) Flush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.handoffMu.Lock()
+	events := s.handoff
+	s.handoff = nil
+	s.handoffMu.Unlock()
+
+	for _, event := range events {
+		s.queue(event)
+	}
+}
+
+// queue writes a delivery of the event to the repository for every webhook
+// registered for its type
+func (s *WebhookService) queue(event *model.AuctionEvent) {
+	var webhooks []*model.Webhook
+	for _, webhook := range s.webhooks.ListWebhooks() {
+		if webhook.Wants(event.Type) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	// Receivers are registered by admins, so they get user IDs in full
+	payload, err := json.Marshal(event.Public(func(userID string) string { return userID }))
+	if err != nil {
+		log.Printf("webhooks: failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	now := s.clock.Now()
+	deliveries := make([]*model.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = &model.WebhookDelivery{
+			ID:            fmt.Sprintf("delivery-%d", s.webhooks.GetNextDeliveryID()),
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			AuctionID:     event.AuctionID(),
+			Sequence:      event.Sequence,
+			Payload:       payload,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+	}
+	if err := s.webhooks.AddDeliveries(deliveries); err != nil {
+		log.Printf("webhooks: failed to queue %s event: %v", event.Type, err)
+		return
+	}
+	s.notify()
+}
+
+// Run queues handed over events and delivers queued ones until ctx is cancelled
+func (s *WebhookService) Run(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.handedOff:
+				s.Flush()
+			}
+		}
+	}()
+
+	for {
+		wait := s.DeliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-s.clock.After(wait):
+		}
+	}
+}
+
+// DeliverDue queues the events handed over so far, attempts the deliveries
+// whose time has come and returns how long until the next one is due. Up to
+// the configured number of webhooks are posted to at once, each in queue
+// order: a webhook's deliveries wait behind its oldest pending one, and stop
+// for this round when one fails.
+func (s *WebhookService) DeliverDue(ctx context.Context) time.Duration {
+	s.Flush()
+	now := s.clock.Now()
+
+	var order []string
+	due := make(map[string][]string)
+	blocked := make(map[string]bool)
+	for _, delivery := range s.webhooks.ListDeliveries(model.DeliveryPending) {
+		if blocked[delivery.WebhookID] {
+			continue
+		}
+		if !delivery.IsDue(now) {
+			blocked[delivery.WebhookID] = true
+			continue
+		}
+		if _, ok := due[delivery.WebhookID]; !ok {
+			order = append(order, delivery.WebhookID)
+		}
+		due[delivery.WebhookID] = append(due[delivery.WebhookID], delivery.ID)
+	}
+
+	webhookIDs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(s.workers, len(order)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for webhookID := range webhookIDs {
+				for _, id := range due[webhookID] {
+					if ctx.Err() != nil || !s.attempt(ctx, id) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	for _, webhookID := range order {
+		webhookIDs <- webhookID
+	}
+	close(webhookIDs)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	// Only each webhook's oldest pending delivery can be next
+	wait := webhookIdleWait
+	seen := make(map[string]bool)
+	for _, delivery := range s.webhooks.ListDeliveries(model.DeliveryPending) {
+		if !seen[delivery.WebhookID] {
+			seen[delivery.WebhookID] = true
+			wait = min(wait, max(0, delivery.NextAttemptAt.Sub(now)))
+		}
+	}
+	return wait
+}
+
+// attempt posts a delivery once, removing it from the queue if the receiver
+// accepts it and scheduling a retry or dead-lettering it if not. It reports
+// whether the receiver accepted it.
+func (s *WebhookService) attempt(ctx context.Context, id string) bool {
+	delivery := s.webhooks.GetDelivery(id)
+	if delivery == nil {
+		return true
+	}
+	webhook := s.webhooks.GetWebhook(delivery.WebhookID)
+	if webhook == nil {
+		s.webhooks.DeleteDelivery(id)
+		return true
+	}
+
+	postErr := s.post(ctx, webhook, delivery)
+	if postErr == nil {
+		if err := s.webhooks.DeleteDelivery(id); err != nil {
+			log.Printf("webhooks: failed to remove %s from the queue: %v", id, err)
+		}
+		return true
+	}
+
+	err := s.webhooks.UpdateDelivery(id, func(delivery *model.WebhookDelivery) error {
+		delivery.Attempts++
+		delivery.LastError = postErr.Error()
+		if delivery.Attempts >= s.attempts {
+			delivery.Status = model.DeliveryDead
+			log.Printf("webhooks: %s to %s failed %d times, moved to the dead-letter list: %v", id, webhook.URL, delivery.Attempts, postErr)
+			return nil
+		}
+		delivery.NextAttemptAt = s.clock.Now().Add(s.retryAfter(delivery.Attempts))
+		return nil
+	})
+	if err != nil {
+		log.Printf("webhooks: failed to reschedule %s: %v", id, err)
+	}
+	return false
+}
+
+// post sends the delivery's payload, signed, to the webhook's URL
+func (s *WebhookService) post(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := s.clock.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponse))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return nil
+}
+
+// retryAfter returns the wait before the retry that follows the given number
+// of failed attempts
+func (s *WebhookService) retryAfter(attempts int) time.Duration {
+	wait := s.backoff
+	for i := 1; i < attempts && wait < maxWebhookBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxWebhookBackoff)
+}
+
+// notify wakes Run without blocking if it is already awake
+func (s *WebhookService) notify() {
+	signal(s.wake)
+}
+
+// signal sends on a channel with room for one signal, unless one is pending
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// SignWebhook returns the signature header value of a delivery made at the
+// given Unix time
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook reports whether signature is the one SignWebhook gives, for
+// receivers checking a delivery
+func VerifyWebhook(secret string, timestamp int64, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, payload)), []byte(signature))
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+const testWebhookSecret = "0123456789abcdef"
+
+// receiver is an httptest webhook endpoint that records what it is sent and
+// answers with status
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusNoContent}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *receiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// newWebhookAuction wires a webhook service to a new auction service's
+// broadcasts, as main does
+func newWebhookAuction(t *testing.T, clk *clock.Fake, opts ...WebhookOption) (*AuctionService, *WebhookService) {
+	t.Helper()
+	repo := store.NewMemoryRepository()
+	st := store.NewAuctionStoreWithRepository(repo)
+	webhooks := NewWebhookService(repo, append([]WebhookOption{WithWebhookClock(clk)}, opts...)...)
+	st.AddListener(webhooks.Enqueue)
+	return NewAuctionService(st, WithClock(clk)), webhooks
+}
+
+func TestWebhooks_DeliversSignedEvents(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc, webhooks := newWebhookAuction(t, clk)
+	recv := newReceiver(t)
+
+	webhook, err := webhooks.RegisterWebhook(context.Background(), "admin", recv.URL, []model.AuctionEventType{model.EventBidPlaced}, testWebhookSecret)
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	reserve := usd(500)
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30, ReservePrice: &reserve})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(150)); err != nil {
+		t.Fatalf("bid placement failed: %v", err)
+	}
+
+	webhooks.DeliverDue(context.Background())
+	if recv.received() != 1 {
+		t.Fatalf("expected the BID_PLACED event alone, got %d deliveries", recv.received())
+	}
+	req, body := recv.requests[0], recv.bodies[0]
+	if req.Header.Get(WebhookEventHeader) != string(model.EventBidPlaced) || req.Header.Get(WebhookDeliveryHeader) == "" {
+		t.Errorf("expected event and delivery headers, got %v", req.Header)
+	}
+	timestamp, _ := strconv.ParseInt(req.Header.Get(WebhookTimestampHeader), 10, 64)
+	if !VerifyWebhook(webhook.Secret, timestamp, body, req.Header.Get(WebhookSignatureHeader)) {
+		t.Errorf("expected a valid signature, got %q", req.Header.Get(WebhookSignatureHeader))
+	}
+	if VerifyWebhook("another secret entirely", timestamp, body, req.Header.Get(WebhookSignatureHeader)) {
+		t.Error("expected the signature to depend on the secret")
+	}
+
+	var event model.AuctionEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("invalid payload %q: %v", body, err)
+	}
+	if event.Bid == nil || event.Bid.UserID != "alice" || event.Sequence != svc.LatestSequence(auction.ID) {
+		t.Errorf("expected alice's bid at the latest sequence, got %+v", event)
+	}
+	if event.Auction.ReservePrice != nil {
+		t.Errorf("expected the reserve to be left out, got %s", event.Auction.ReservePrice)
+	}
+	if pending := webhooks.ListDeliveries(model.DeliveryPending); len(pending) != 0 {
+		t.Errorf("expected the delivered event to leave the queue, got %+v", pending)
+	}
+}
+
+func TestWebhooks_BacksOffThenDeadLetters(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc, webhooks := newWebhookAuction(t, clk, WithWebhookRetries(3, time.Second))
+	recv := newReceiver(t)
+	recv.respond(http.StatusInternalServerError)
+
+	if _, err := webhooks.RegisterWebhook(context.Background(), "admin", recv.URL, []model.AuctionEventType{model.EventAuctionStarted}, testWebhookSecret); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	if _, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30}); err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	// Attempts at 0s, 1s and 3s: the wait doubles after each failure
+	if wait := webhooks.DeliverDue(context.Background()); wait != time.Second || recv.received() != 1 {
+		t.Fatalf("expected a failed attempt and a retry in 1s, got %d attempts and %s", recv.received(), wait)
+	}
+	clk.Advance(500 * time.Millisecond)
+	if webhooks.DeliverDue(context.Background()); recv.received() != 1 {
+		t.Fatalf("expected no attempt before the retry is due, got %d", recv.received())
+	}
+	clk.Advance(500 * time.Millisecond)
+	if wait := webhooks.DeliverDue(context.Background()); wait != 2*time.Second || recv.received() != 2 {
+		t.Fatalf("expected a second attempt and a retry in 2s, got %d attempts and %s", recv.received(), wait)
+	}
+	clk.Advance(2 * time.Second)
+	webhooks.DeliverDue(context.Background())
+
+	dead := webhooks.ListDeliveries(model.DeliveryDead)
+	if recv.received() != 3 || len(dead) != 1 {
+		t.Fatalf("expected the delivery to be dead-lettered after 3 attempts, got %d attempts and %+v", recv.received(), dead)
+	}
+	if dead[0].Attempts != 3 || !strings.Contains(dead[0].LastError, "500") {
+		t.Errorf("expected 3 attempts ending in a 500, got %d and %q", dead[0].Attempts, dead[0].LastError)
+	}
+
+	recv.respond(http.StatusOK)
+	if _, err := webhooks.RetryDelivery(context.Background(), dead[0].ID); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	webhooks.DeliverDue(context.Background())
+	if recv.received() != 4 || len(webhooks.ListDeliveries(model.DeliveryDead)) != 0 || len(webhooks.ListDeliveries(model.DeliveryPending)) != 0 {
+		t.Errorf("expected the retried delivery to go through and leave the queue, got %d attempts", recv.received())
+	}
+	if _, err := webhooks.RetryDelivery(context.Background(), dead[0].ID); !errors.Is(err, model.ErrDeliveryNotFound) {
+		t.Errorf("expected ErrDeliveryNotFound for a delivered event, got %v", err)
+	}
+}
+
+// lockCheckingRepository fails the test if deliveries are written while the
+// auction store's lock is held, by taking the lock itself
+type lockCheckingRepository struct {
+	*store.MemoryRepository
+	st *store.AuctionStore
+}
+
+func (r *lockCheckingRepository) AddDeliveries(deliveries []*model.WebhookDelivery) error {
+	r.st.GetSubscriberCount()
+	return r.MemoryRepository.AddDeliveries(deliveries)
+}
+
+func TestWebhooks_QueuesOutsideBroadcastLock(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	st := store.NewAuctionStore()
+	repo := &lockCheckingRepository{MemoryRepository: store.NewMemoryRepository(), st: st}
+	webhooks := NewWebhookService(repo, WithWebhookClock(clk))
+	st.AddListener(webhooks.Enqueue)
+	svc := NewAuctionService(st, WithClock(clk))
+	recv := newReceiver(t)
+
+	if _, err := webhooks.RegisterWebhook(context.Background(), "admin", recv.URL, []model.AuctionEventType{model.EventAuctionStarted}, testWebhookSecret); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go webhooks.Run(ctx)
+
+	created := make(chan error, 1)
+	go func() {
+		_, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+		created <- err
+	}()
+	select {
+	case err := <-created:
+		if err != nil {
+			t.Fatalf("auction creation failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast blocked: deliveries were written under the store's lock")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for recv.received() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if recv.received() != 1 {
+		t.Errorf("expected Run to queue and deliver the AUCTION_STARTED event, got %d deliveries", recv.received())
+	}
+}
+
+func TestWebhooks_SlowReceiverDoesNotHoldUpOthers(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc, webhooks := newWebhookAuction(t, clk)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	recv := newReceiver(t)
+
+	started := []model.AuctionEventType{model.EventAuctionStarted}
+	for _, url := range []string{slow.URL, recv.URL} {
+		if _, err := webhooks.RegisterWebhook(context.Background(), "admin", url, started, testWebhookSecret); err != nil {
+			t.Fatalf("registration failed: %v", err)
+		}
+	}
+	if _, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30}); err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		webhooks.DeliverDue(context.Background())
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for recv.received() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if recv.received() != 1 {
+		t.Errorf("expected the healthy receiver to get its event while the slow one hangs, got %d", recv.received())
+	}
+	close(release)
+	<-done
+}
+
+func TestWebhooks_KeepsOrderPerWebhook(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	svc, webhooks := newWebhookAuction(t, clk, WithWebhookRetries(3, time.Second))
+	recv := newReceiver(t)
+	recv.respond(http.StatusServiceUnavailable)
+
+	if _, err := webhooks.RegisterWebhook(context.Background(), "admin", recv.URL, []model.AuctionEventType{model.EventBidPlaced}, testWebhookSecret); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	auction, err := svc.CreateAuction(context.Background(), CreateAuctionParams{StartingBid: usd(100), Duration: 30})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	for _, units := range []int64{150, 160} {
+		if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(units)); err != nil {
+			t.Fatalf("bid placement failed: %v", err)
+		}
+	}
+
+	// The second bid waits behind the first, which failed
+	if wait := webhooks.DeliverDue(context.Background()); recv.received() != 1 || wait != time.Second {
+		t.Fatalf("expected one failed attempt and a retry in 1s, got %d attempts and %s", recv.received(), wait)
+	}
+	recv.respond(http.StatusOK)
+	clk.Advance(time.Second)
+	webhooks.DeliverDue(context.Background())
+
+	if recv.received() != 3 {
+		t.Fatalf("expected the retry and then the second bid, got %d attempts", recv.received())
+	}
+	var amounts []model.Money
+	for _, body := range recv.bodies[1:] {
+		var event model.AuctionEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("invalid payload %q: %v", body, err)
+		}
+		amounts = append(amounts, event.Bid.Amount)
+	}
+	if amounts[0] != usd(150) || amounts[1] != usd(160) {
+		t.Errorf("expected the bids in the order they were placed, got %v", amounts)
+	}
+}
+
+func TestWebhooks_RegistrationValidation(t *testing.T) {
+	_, webhooks := newWebhookAuction(t, clock.NewFake(time.Now()))
+	bidPlaced := []model.AuctionEventType{model.EventBidPlaced}
+
+	cases := map[string]struct {
+		url        string
+		eventTypes []model.AuctionEventType
+		secret     string
+		want       error
+	}{
+		"relative url":  {"/hook", bidPlaced, testWebhookSecret, model.ErrInvalidWebhookURL},
+		"ftp url":       {"ftp://example.com/hook", bidPlaced, testWebhookSecret, model.ErrInvalidWebhookURL},
+		"no events":     {"https://example.com/hook", nil, testWebhookSecret, model.ErrInvalidWebhookEvents},
+		"resync":        {"https://example.com/hook", []model.AuctionEventType{model.EventResyncRequired}, testWebhookSecret, model.ErrInvalidWebhookEvents},
+		"short secret":  {"https://example.com/hook", bidPlaced, "secret", model.ErrInvalidWebhookSecret},
+		"valid webhook": {"https://example.com/hook", bidPlaced, testWebhookSecret, nil},
+	}
+	for name, tc := range cases {
+		if _, err := webhooks.RegisterWebhook(context.Background(), "admin", tc.url, tc.eventTypes, tc.secret); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+
+	if _, err := webhooks.DeleteWebhook(context.Background(), "webhook-99"); !errors.Is(err, model.ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v", err)
+	}
+}
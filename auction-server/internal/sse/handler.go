@@ -0,0 +1,126 @@
+// Package sse serves auction events over Server-Sent Events, as plain JSON
+// without GraphQL, and lets GraphQL subscriptions over SSE resume from a
+// Last-Event-ID
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// DefaultHeartbeat is how often an idle stream sends a comment, so proxies
+// don't close it
+const DefaultHeartbeat = 15 * time.Second
+
+// EventSource is the part of the auction service a stream follows
+type EventSource interface {
+	GetAuction(id string) *model.Auction
+	Follow(id string, auctionID string, afterSequence *int) (chan *model.AuctionEvent, []*model.AuctionEvent)
+	Unsubscribe(id string)
+}
+
+// Handler serves GET /events/{auctionId}: the auction's events as JSON, each
+// with its sequence as the SSE id, so a reconnecting EventSource resumes from
+// its Last-Event-ID. User IDs are redacted as the GraphQL API does, unless the
+// caller is an admin.
+type Handler struct {
+	source      EventSource
+	heartbeat   time.Duration
+	subscribers atomic.Int64
+}
+
+// NewHandler creates a handler streaming events from source
+func NewHandler(source EventSource) *Handler {
+	return &Handler{source: source, heartbeat: DefaultHeartbeat}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auctionID := r.PathValue("auctionId")
+	if h.source.GetAuction(auctionID) == nil {
+		http.Error(w, fmt.Sprintf("auction %s not found", auctionID), http.StatusNotFound)
+		return
+	}
+
+	var afterSequence *int
+	sequence, ok, err := lastEventID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ok {
+		afterSequence = &sequence
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	subscriberID := fmt.Sprintf("sse-%d", h.subscribers.Add(1))
+	events, backlog := h.source.Follow(subscriberID, auctionID, afterSequence)
+	defer h.source.Unsubscribe(subscriberID)
+
+	ctx := r.Context()
+	for _, event := range backlog {
+		if err := writeEvent(ctx, w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event := <-events:
+			if err := writeEvent(ctx, w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one event in SSE framing, redacted for the caller
+func writeEvent(ctx context.Context, w http.ResponseWriter, event *model.AuctionEvent) error {
+	data, err := json.Marshal(event.Public(redactor(ctx)))
+	if err != nil {
+		// An event that can't be encoded is dropped rather than ending the stream
+		log.Printf("sse: failed to encode %s event: %v", event.Type, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err
+}
+
+// redactor returns how user IDs are shown to the caller: in full to admins,
+// as @redactUnless(roles: [ADMIN]) shows them to everyone else
+func redactor(ctx context.Context) func(string) string {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.HasRole(string(model.RoleAdmin)) {
+		return func(userID string) string { return userID }
+	}
+	return func(userID string) string { return auth.RedactUserID(ctx, userID) }
+}
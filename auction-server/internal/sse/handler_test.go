@@ -0,0 +1,167 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/service"
+	"github.com/micahli/fl-auction/auction-server/internal/store"
+)
+
+func usd(units int64) model.Money {
+	return model.WholeUnits(units, "USD")
+}
+
+type frame struct {
+	id    int
+	event string
+	data  model.AuctionEvent
+}
+
+// stream opens the auction's event stream as the given user, resuming from
+// lastEventID unless it is empty, and returns a func reading the next event
+func stream(t *testing.T, h http.Handler, subject string, auctionID string, lastEventID string) func() frame {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle("GET /events/{auctionId}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := auth.WithClaims(r.Context(), &auth.Claims{Subject: subject, Roles: []string{"BIDDER"}})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/"+auctionID, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %q", resp.StatusCode, got)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	return func() frame {
+		t.Helper()
+		var f frame
+		for lines.Scan() {
+			line := lines.Text()
+			switch {
+			case line == "" && f.event != "":
+				return f
+			case strings.HasPrefix(line, "id: "):
+				f.id, _ = strconv.Atoi(strings.TrimPrefix(line, "id: "))
+			case strings.HasPrefix(line, "event: "):
+				f.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &f.data); err != nil {
+					t.Fatalf("invalid event data %q: %v", line, err)
+				}
+			}
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return f
+	}
+}
+
+func newAuction(t *testing.T) (*service.AuctionService, *model.Auction) {
+	t.Helper()
+	svc := service.NewAuctionService(store.NewAuctionStore())
+	reserve := usd(500)
+	auction, err := svc.CreateAuction(context.Background(), service.CreateAuctionParams{StartingBid: usd(100), Duration: 30, ReservePrice: &reserve})
+	if err != nil {
+		t.Fatalf("auction creation failed: %v", err)
+	}
+	return svc, auction
+}
+
+func TestHandler_ResumesFromLastEventID(t *testing.T) {
+	svc, auction := newAuction(t)
+	for _, bid := range []struct {
+		user   string
+		amount int64
+	}{{"alice", 150}, {"bob", 160}} {
+		if _, err := svc.PlaceBid(context.Background(), auction.ID, bid.user, usd(bid.amount)); err != nil {
+			t.Fatalf("bid placement failed: %v", err)
+		}
+	}
+	latest := svc.LatestSequence(auction.ID)
+
+	next := stream(t, NewHandler(svc), "alice", auction.ID, strconv.Itoa(latest-1))
+	got := next()
+	if got.id != latest || got.event != string(model.EventBidPlaced) || got.data.Sequence != latest {
+		t.Fatalf("expected the missed BID_PLACED %d, got %+v", latest, got)
+	}
+	if got.data.Bid.UserID != "b***" || *got.data.Auction.CurrentWinner != "b***" {
+		t.Errorf("expected bob to be redacted, got bid by %q and winner %q", got.data.Bid.UserID, *got.data.Auction.CurrentWinner)
+	}
+	if got.data.Auction.ReservePrice != nil || got.data.Auction.Bids != nil {
+		t.Errorf("expected the reserve and bid history to be left out, got %+v", got.data.Auction)
+	}
+
+	if _, err := svc.PlaceBid(context.Background(), auction.ID, "alice", usd(170)); err != nil {
+		t.Fatalf("bid placement failed: %v", err)
+	}
+	got = next()
+	if got.id != latest+1 || got.data.Bid.UserID != "alice" {
+		t.Errorf("expected alice's live bid %d with her own ID, got %+v", latest+1, got)
+	}
+}
+
+func TestHandler_NewSubscriberGetsSnapshot(t *testing.T) {
+	svc, auction := newAuction(t)
+
+	got := stream(t, NewHandler(svc), "alice", auction.ID, "")()
+	if got.event != string(model.EventAuctionStarted) || got.id != svc.LatestSequence(auction.ID) || got.data.Auction.ID != auction.ID {
+		t.Errorf("expected a snapshot at the latest sequence, got %+v", got)
+	}
+}
+
+func TestHandler_StaleLastEventIDRequiresResync(t *testing.T) {
+	svc, auction := newAuction(t)
+
+	got := stream(t, NewHandler(svc), "alice", auction.ID, "99")()
+	if got.event != string(model.EventResyncRequired) || got.id != svc.LatestSequence(auction.ID) {
+		t.Errorf("expected RESYNC_REQUIRED at the latest sequence, got %+v", got)
+	}
+}
+
+func TestHandler_RefusesBadRequests(t *testing.T) {
+	svc, auction := newAuction(t)
+	mux := http.NewServeMux()
+	mux.Handle("GET /events/{auctionId}", NewHandler(svc))
+
+	cases := map[string]struct {
+		path        string
+		lastEventID string
+		want        int
+	}{
+		"unknown auction":   {"/events/auction-99", "", http.StatusNotFound},
+		"bad last event id": {"/events/" + auction.ID, "latest", http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", tc.lastEventID)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", name, tc.want, rec.Code)
+		}
+	}
+}
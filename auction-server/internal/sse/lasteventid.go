@@ -0,0 +1,51 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type lastEventIDKey struct{}
+
+// WithLastEventID returns a copy of ctx carrying the sequence a client last saw
+func WithLastEventID(ctx context.Context, sequence int) context.Context {
+	return context.WithValue(ctx, lastEventIDKey{}, sequence)
+}
+
+// LastEventIDFromContext returns the sequence stored by LastEventID, if any
+func LastEventIDFromContext(ctx context.Context) (int, bool) {
+	sequence, ok := ctx.Value(lastEventIDKey{}).(int)
+	return sequence, ok
+}
+
+// LastEventID stores the sequence of a reconnecting client's Last-Event-ID
+// header in the request context, so a GraphQL subscription over SSE resumes
+// where it left off. A header that isn't a sequence is ignored.
+func LastEventID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sequence, ok, err := lastEventID(r); ok && err == nil {
+			r = r.WithContext(WithLastEventID(r.Context(), sequence))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lastEventID reads the sequence from the Last-Event-ID header or, for clients
+// that can't set headers, the lastEventId query parameter
+func lastEventID(r *http.Request) (int, bool, error) {
+	value := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if value == "" {
+		value = strings.TrimSpace(r.URL.Query().Get("lastEventId"))
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	sequence, err := strconv.Atoi(value)
+	if err != nil || sequence < 0 {
+		return 0, true, fmt.Errorf("invalid Last-Event-ID %q", value)
+	}
+	return sequence, true, nil
+}
@@ -6,82 +6,110 @@ import (
 	"github.com/micahli/fl-auction/auction-server/internal/model"
 )
 
-// AuctionStore manages auction state and subscriptions
+// eventBufferSize is how many recent events are kept per auction for resuming subscribers
+const eventBufferSize = 100
+
+// eventBuffer holds the most recent events of one auction
+type eventBuffer struct {
+	lastSequence int
+	events       []*model.AuctionEvent
+}
+
+// subscription is a subscriber channel optionally scoped to one auction
+type subscription struct {
+	auctionID string
+	ch        chan *model.AuctionEvent
+}
+
+// Listener is handed every broadcast event once it has its sequence number.
+// Unlike subscribers, listeners are never skipped, so they must not block.
+type Listener func(event *model.AuctionEvent)
+
+// AuctionStore combines auction persistence with event subscriptions.
+// Auction and bid operations are delegated to the embedded AuctionRepository.
 type AuctionStore struct {
-	mu             sync.RWMutex
-	currentAuction *model.Auction
-	subscribers    map[string]chan *model.AuctionEvent
-	nextBidID      int
+	AuctionRepository
+
+	mu          sync.RWMutex
+	subscribers map[string]*subscription
+	buffers     map[string]*eventBuffer
+	listeners   []Listener
 }
 
-// NewAuctionStore creates a new auction store
+// NewAuctionStore creates a new auction store backed by memory
 func NewAuctionStore() *AuctionStore {
-	return &AuctionStore{
-		subscribers: make(map[string]chan *model.AuctionEvent),
-		nextBidID:   1,
-	}
+	return NewAuctionStoreWithRepository(NewMemoryRepository())
 }
 
-// GetCurrentAuction returns the current active auction
-func (s *AuctionStore) GetCurrentAuction() *model.Auction {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.currentAuction
+// NewAuctionStoreWithRepository creates a new auction store backed by the given repository
+func NewAuctionStoreWithRepository(repo AuctionRepository) *AuctionStore {
+	return &AuctionStore{
+		AuctionRepository: repo,
+		subscribers:       make(map[string]*subscription),
+		buffers:           make(map[string]*eventBuffer),
+	}
 }
 
-// SetCurrentAuction sets the current auction
-func (s *AuctionStore) SetCurrentAuction(auction *model.Auction) {
+// AddListener registers a listener for every event broadcast from now on
+func (s *AuctionStore) AddListener(l Listener) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.currentAuction = auction
+	s.listeners = append(s.listeners, l)
 }
 
-// UpdateAuction updates the current auction atomically
-func (s *AuctionStore) UpdateAuction(updateFn func(*model.Auction) error) error {
+// Subscribe creates a new subscription channel for auction events.
+// An empty auctionID subscribes to events from every auction.
+func (s *AuctionStore) Subscribe(id string, auctionID string) chan *model.AuctionEvent {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.currentAuction == nil {
-		return model.ErrNoActiveAuction
-	}
-
-	return updateFn(s.currentAuction)
+	ch := make(chan *model.AuctionEvent, 10)
+	s.subscribers[id] = &subscription{auctionID: auctionID, ch: ch}
+	return ch
 }
 
-// AddBid adds a bid to the current auction and returns the bid ID
-func (s *AuctionStore) AddBid(bid *model.Bid) error {
+// Resume subscribes to a single auction and returns the buffered events that
+// followed afterSequence, so the subscriber misses nothing in between. It
+// returns ok=false, along with the auction's latest sequence, when some of
+// those events have already been evicted from the buffer.
+func (s *AuctionStore) Resume(id string, auctionID string, afterSequence int) (ch chan *model.AuctionEvent, missed []*model.AuctionEvent, latest int, ok bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.currentAuction == nil {
-		return model.ErrNoActiveAuction
-	}
-
-	s.currentAuction.Bids = append(s.currentAuction.Bids, *bid)
-	s.currentAuction.CurrentBid = bid.Amount
-	s.currentAuction.CurrentWinner = &bid.UserID
+	ch = make(chan *model.AuctionEvent, 10)
+	s.subscribers[id] = &subscription{auctionID: auctionID, ch: ch}
 
-	return nil
-}
+	buf := s.buffers[auctionID]
+	if buf == nil {
+		// Nothing has happened since startup; only a fresh client can be in sync
+		return ch, nil, 0, afterSequence == 0
+	}
+	if afterSequence > buf.lastSequence {
+		// The client saw sequences this store never issued, e.g. before a restart
+		return ch, nil, buf.lastSequence, false
+	}
+	if afterSequence == buf.lastSequence {
+		return ch, nil, buf.lastSequence, true
+	}
 
-// GetNextBidID returns the next available bid ID
-func (s *AuctionStore) GetNextBidID() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	first := buf.lastSequence - len(buf.events) + 1
+	if afterSequence+1 < first {
+		return ch, nil, buf.lastSequence, false
+	}
 
-	id := s.nextBidID
-	s.nextBidID++
-	return id
+	missed = append(missed, buf.events[afterSequence+1-first:]...)
+	return ch, missed, buf.lastSequence, true
 }
 
-// Subscribe creates a new subscription channel for auction events
-func (s *AuctionStore) Subscribe(id string) chan *model.AuctionEvent {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// LatestSequence returns the sequence number of the most recent event of an auction
+func (s *AuctionStore) LatestSequence(auctionID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	ch := make(chan *model.AuctionEvent, 10)
-	s.subscribers[id] = ch
-	return ch
+	if buf := s.buffers[auctionID]; buf != nil {
+		return buf.lastSequence
+	}
+	return 0
 }
 
 // Unsubscribe removes a subscription
@@ -89,22 +117,53 @@ func (s *AuctionStore) Unsubscribe(id string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if ch, exists := s.subscribers[id]; exists {
-		close(ch)
+	if sub, exists := s.subscribers[id]; exists {
+		close(sub.ch)
 		delete(s.subscribers, id)
 	}
 }
 
-// Broadcast sends an event to all subscribers
+// Broadcast assigns the event the next sequence number of its auction, keeps it
+// for resuming subscribers and sends it to all subscribers of that auction. The
+// event carries a snapshot of the auction, so it shows the auction as it was
+// when the event happened however much later it is read. Callers must not
+// change the auction while it is being broadcast.
 func (s *AuctionStore) Broadcast(event *model.AuctionEvent) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	if event.Auction != nil {
+		event.Auction = event.Auction.Snapshot()
+	}
 
-	for _, ch := range s.subscribers {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if auctionID := event.AuctionID(); auctionID != "" {
+		buf := s.buffers[auctionID]
+		if buf == nil {
+			buf = &eventBuffer{}
+			s.buffers[auctionID] = buf
+		}
+		buf.lastSequence++
+		event.Sequence = buf.lastSequence
+		buf.events = append(buf.events, event)
+		if len(buf.events) > eventBufferSize {
+			buf.events = buf.events[len(buf.events)-eventBufferSize:]
+		}
+	}
+
+	// Listeners run under the lock, so they see events in sequence order
+	for _, listener := range s.listeners {
+		listener(event)
+	}
+
+	for _, sub := range s.subscribers {
+		if sub.auctionID != "" && sub.auctionID != event.AuctionID() {
+			continue
+		}
 		select {
-		case ch <- event:
+		case sub.ch <- event:
 		default:
-			// Skip slow consumers to prevent blocking
+			// Skip slow consumers to prevent blocking; they can
+			// detect the gap from the sequence numbers and resume
 		}
 	}
 }
@@ -115,10 +174,3 @@ func (s *AuctionStore) GetSubscriberCount() int {
 	defer s.mu.RUnlock()
 	return len(s.subscribers)
 }
-
-// Clear removes the current auction (useful for testing)
-func (s *AuctionStore) Clear() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.currentAuction = nil
-}
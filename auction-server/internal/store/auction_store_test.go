@@ -0,0 +1,81 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+func TestBroadcast_AssignsPerAuctionSequence(t *testing.T) {
+	st := NewAuctionStore()
+	a := newTestAuction("auction-1")
+	b := newTestAuction("auction-2")
+
+	st.Broadcast(model.NewAuctionStartedEvent(a))
+	st.Broadcast(model.NewAuctionStartedEvent(b))
+	event := model.NewAuctionEndedEvent(a)
+	st.Broadcast(event)
+
+	if event.Sequence != 2 {
+		t.Errorf("expected sequence 2, got %d", event.Sequence)
+	}
+	if got := st.LatestSequence("auction-2"); got != 1 {
+		t.Errorf("expected latest sequence 1 for auction-2, got %d", got)
+	}
+}
+
+func TestResume_ReplaysMissedEvents(t *testing.T) {
+	st := NewAuctionStore()
+	auction := newTestAuction("auction-1")
+	for i := 0; i < 5; i++ {
+		st.Broadcast(model.NewBidPlacedEvent(auction, nil))
+	}
+
+	_, missed, latest, ok := st.Resume("sub-1", auction.ID, 3)
+	if !ok {
+		t.Fatal("expected resume to succeed")
+	}
+	if latest != 5 {
+		t.Errorf("expected latest sequence 5, got %d", latest)
+	}
+	if len(missed) != 2 || missed[0].Sequence != 4 || missed[1].Sequence != 5 {
+		t.Errorf("expected events 4 and 5, got %+v", missed)
+	}
+}
+
+func TestResume_EventsKeepTheirAuctionState(t *testing.T) {
+	st := NewAuctionStore()
+	auction := newTestAuction("auction-1")
+	for _, units := range []int64{150, 160} {
+		bid := model.Bid{ID: "bid", AuctionID: auction.ID, UserID: "alice", Amount: usd(units)}
+		auction.ApplyBid(bid)
+		st.Broadcast(model.NewBidPlacedEvent(auction, &bid))
+	}
+	auction.End(model.AuctionStatusEnded)
+
+	_, missed, _, ok := st.Resume("sub-1", auction.ID, 0)
+	if !ok || len(missed) != 2 {
+		t.Fatalf("expected both events back, got %+v", missed)
+	}
+	if first := missed[0].Auction; first.CurrentBid != usd(150) || len(first.Bids) != 1 || first.Status != model.AuctionStatusActive {
+		t.Errorf("expected the first event to show the auction active at 150.0, got %s with %d bids, %s", first.CurrentBid, len(first.Bids), first.Status)
+	}
+}
+
+func TestResume_EvictedRequiresResync(t *testing.T) {
+	st := NewAuctionStore()
+	auction := newTestAuction("auction-1")
+	for i := 0; i < eventBufferSize+10; i++ {
+		st.Broadcast(model.NewBidPlacedEvent(auction, nil))
+	}
+
+	if _, _, _, ok := st.Resume("sub-1", auction.ID, 5); ok {
+		t.Error("expected resync when events were evicted")
+	}
+	if _, missed, _, ok := st.Resume("sub-2", auction.ID, 10); !ok || len(missed) != eventBufferSize {
+		t.Errorf("expected the full buffer to be replayed, got ok=%t len=%d", ok, len(missed))
+	}
+	if _, _, _, ok := st.Resume("sub-3", auction.ID, eventBufferSize+50); ok {
+		t.Error("expected resync for a sequence the store never issued")
+	}
+}
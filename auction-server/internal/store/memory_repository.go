@@ -0,0 +1,332 @@
+package store
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// MemoryRepository is an AuctionRepository, UserRepository and
+// WebhookRepository that keeps everything in memory
+type MemoryRepository struct {
+	mu             sync.RWMutex
+	auctions       map[string]*model.Auction
+	auctionOrder   []string
+	nextAuctionID  int
+	nextBidID      int
+	users          map[string]*model.User
+	userOrder      []string
+	webhooks       map[string]*model.Webhook
+	deliveries     map[string]*model.WebhookDelivery
+	nextWebhookID  int
+	nextDeliveryID int
+}
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		auctions:       make(map[string]*model.Auction),
+		users:          make(map[string]*model.User),
+		webhooks:       make(map[string]*model.Webhook),
+		deliveries:     make(map[string]*model.WebhookDelivery),
+		nextAuctionID:  1,
+		nextBidID:      1,
+		nextWebhookID:  1,
+		nextDeliveryID: 1,
+	}
+}
+
+// GetAuction returns the auction with the given ID, or nil if it doesn't exist
+func (r *MemoryRepository) GetAuction(id string) *model.Auction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.auctions[id]
+}
+
+// GetCurrentAuction returns the most recently created auction
+func (r *MemoryRepository) GetCurrentAuction() *model.Auction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.auctionOrder) == 0 {
+		return nil
+	}
+	return r.auctions[r.auctionOrder[len(r.auctionOrder)-1]]
+}
+
+// ListAuctions returns all auctions in creation order, optionally filtered by status
+func (r *MemoryRepository) ListAuctions(status *model.AuctionStatus) []*model.Auction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	auctions := make([]*model.Auction, 0, len(r.auctionOrder))
+	for _, id := range r.auctionOrder {
+		auction := r.auctions[id]
+		if status != nil && auction.Status != *status {
+			continue
+		}
+		auctions = append(auctions, auction)
+	}
+	return auctions
+}
+
+// SetAuction inserts or replaces an auction
+func (r *MemoryRepository) SetAuction(auction *model.Auction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.auctions[auction.ID]; !exists {
+		r.auctionOrder = append(r.auctionOrder, auction.ID)
+	}
+	r.auctions[auction.ID] = auction
+	return nil
+}
+
+// UpdateAuction updates the given auction atomically
+func (r *MemoryRepository) UpdateAuction(id string, updateFn func(*model.Auction) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	auction, exists := r.auctions[id]
+	if !exists {
+		return model.ErrAuctionNotFound
+	}
+
+	return updateFn(auction)
+}
+
+// AddBid adds a bid to the auction it references
+func (r *MemoryRepository) AddBid(bid *model.Bid) error {
+	return r.UpdateAuction(bid.AuctionID, func(auction *model.Auction) error {
+		auction.ApplyBid(*bid)
+		return nil
+	})
+}
+
+// GetNextAuctionID returns the next available auction ID
+func (r *MemoryRepository) GetNextAuctionID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextAuctionID
+	r.nextAuctionID++
+	return id
+}
+
+// GetNextBidID returns the next available bid ID
+func (r *MemoryRepository) GetNextBidID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextBidID
+	r.nextBidID++
+	return id
+}
+
+// GetUser returns the user with the given ID, or nil if they never registered
+func (r *MemoryRepository) GetUser(id string) *model.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.users[id]
+}
+
+// ListUsers returns all users in registration order
+func (r *MemoryRepository) ListUsers() []*model.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*model.User, 0, len(r.userOrder))
+	for _, id := range r.userOrder {
+		users = append(users, r.users[id])
+	}
+	return users
+}
+
+// AddUser registers a new user
+func (r *MemoryRepository) AddUser(user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.ID]; exists {
+		return model.ErrAlreadyRegistered
+	}
+	r.users[user.ID] = user
+	r.userOrder = append(r.userOrder, user.ID)
+	return nil
+}
+
+// UpdateUser updates the given user atomically
+func (r *MemoryRepository) UpdateUser(id string, updateFn func(*model.User) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return model.NewUnknownBidderError(id)
+	}
+	return updateFn(user)
+}
+
+// GetWebhook returns the webhook with the given ID, or nil if it doesn't exist
+func (r *MemoryRepository) GetWebhook(id string) *model.Webhook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.webhooks[id]
+}
+
+// ListWebhooks returns all webhooks in registration order
+func (r *MemoryRepository) ListWebhooks() []*model.Webhook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]*model.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	slices.SortFunc(webhooks, func(a, b *model.Webhook) int { return idNumber(a.ID) - idNumber(b.ID) })
+	return webhooks
+}
+
+// AddWebhook registers a new webhook
+func (r *MemoryRepository) AddWebhook(webhook *model.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+// DeleteWebhook removes a webhook along with its queued deliveries
+func (r *MemoryRepository) DeleteWebhook(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.webhooks[id]; !exists {
+		return model.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// GetNextWebhookID returns the next available webhook ID
+func (r *MemoryRepository) GetNextWebhookID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextWebhookID
+	r.nextWebhookID++
+	return id
+}
+
+// GetDelivery returns the delivery with the given ID, or nil if it isn't queued
+func (r *MemoryRepository) GetDelivery(id string) *model.WebhookDelivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.deliveries[id]
+}
+
+// ListDeliveries returns the queued deliveries with the given status in queue order
+func (r *MemoryRepository) ListDeliveries(status model.DeliveryStatus) []*model.WebhookDelivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []*model.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == status {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b *model.WebhookDelivery) int { return idNumber(a.ID) - idNumber(b.ID) })
+	return deliveries
+}
+
+// AddDeliveries queues new deliveries
+func (r *MemoryRepository) AddDeliveries(deliveries []*model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range deliveries {
+		r.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+// UpdateDelivery updates the given delivery atomically
+func (r *MemoryRepository) UpdateDelivery(id string, updateFn func(*model.WebhookDelivery) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, exists := r.deliveries[id]
+	if !exists {
+		return model.ErrDeliveryNotFound
+	}
+	return updateFn(delivery)
+}
+
+// DeleteDelivery removes a delivery from the queue
+func (r *MemoryRepository) DeleteDelivery(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.deliveries, id)
+	return nil
+}
+
+// GetNextDeliveryID returns the next available delivery ID
+func (r *MemoryRepository) GetNextDeliveryID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextDeliveryID
+	r.nextDeliveryID++
+	return id
+}
+
+// Clear removes all auctions (useful for testing)
+func (r *MemoryRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auctions = make(map[string]*model.Auction)
+	r.auctionOrder = nil
+}
+
+// Restore loads auctions rebuilt elsewhere (e.g. replayed from the event log)
+// and moves the ID counters past every auction and bid ID they contain, sealed
+// or not
+func (r *MemoryRepository) Restore(auctions []*model.Auction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, auction := range auctions {
+		if _, exists := r.auctions[auction.ID]; !exists {
+			r.auctionOrder = append(r.auctionOrder, auction.ID)
+		}
+		r.auctions[auction.ID] = auction
+
+		if n := idNumber(auction.ID); n >= r.nextAuctionID {
+			r.nextAuctionID = n + 1
+		}
+		// Sealed bids draw on the same counter but are kept apart until close
+		for _, bids := range [][]model.Bid{auction.Bids, auction.SealedBids} {
+			for _, bid := range bids {
+				if n := idNumber(bid.ID); n >= r.nextBidID {
+					r.nextBidID = n + 1
+				}
+			}
+		}
+	}
+}
+
+// idNumber returns the numeric suffix of IDs such as "auction-12", or 0
+func idNumber(id string) int {
+	n, err := strconv.Atoi(id[strings.LastIndexByte(id, '-')+1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package store
+
+import "github.com/micahli/fl-auction/auction-server/internal/model"
+
+// AuctionRepository persists auctions and their bids. Implementations must be
+// safe for concurrent use and must hand out stable *model.Auction pointers, since
+// the service layer holds on to them between calls.
+type AuctionRepository interface {
+	// GetAuction returns the auction with the given ID, or nil if it doesn't exist
+	GetAuction(id string) *model.Auction
+	// GetCurrentAuction returns the most recently created auction
+	GetCurrentAuction() *model.Auction
+	// ListAuctions returns all auctions in creation order, optionally filtered by status
+	ListAuctions(status *model.AuctionStatus) []*model.Auction
+	// SetAuction inserts or replaces an auction
+	SetAuction(auction *model.Auction) error
+	// UpdateAuction applies updateFn to the given auction atomically and persists the result
+	UpdateAuction(id string, updateFn func(*model.Auction) error) error
+	// AddBid records a bid and makes it the leading bid of its auction
+	AddBid(bid *model.Bid) error
+	// GetNextAuctionID returns the next available auction ID
+	GetNextAuctionID() int
+	// GetNextBidID returns the next available bid ID
+	GetNextBidID() int
+}
+
+// UserRepository persists registered bidders. Like AuctionRepository, it hands
+// out stable *model.User pointers.
+type UserRepository interface {
+	// GetUser returns the user with the given ID, or nil if they never registered
+	GetUser(id string) *model.User
+	// ListUsers returns all users in registration order
+	ListUsers() []*model.User
+	// AddUser registers a new user, failing with model.ErrAlreadyRegistered if the ID is taken
+	AddUser(user *model.User) error
+	// UpdateUser applies updateFn to the given user atomically and persists the result
+	UpdateUser(id string, updateFn func(*model.User) error) error
+}
+
+// WebhookRepository persists webhooks and the queue of deliveries to them.
+// Like the others, it hands out stable pointers.
+type WebhookRepository interface {
+	// GetWebhook returns the webhook with the given ID, or nil if it doesn't exist
+	GetWebhook(id string) *model.Webhook
+	// ListWebhooks returns all webhooks in registration order
+	ListWebhooks() []*model.Webhook
+	// AddWebhook registers a new webhook
+	AddWebhook(webhook *model.Webhook) error
+	// DeleteWebhook removes a webhook along with its queued deliveries
+	DeleteWebhook(id string) error
+	// GetNextWebhookID returns the next available webhook ID
+	GetNextWebhookID() int
+	// GetDelivery returns the delivery with the given ID, or nil if it isn't queued
+	GetDelivery(id string) *model.WebhookDelivery
+	// ListDeliveries returns the queued deliveries with the given status in queue order
+	ListDeliveries(status model.DeliveryStatus) []*model.WebhookDelivery
+	// AddDeliveries queues new deliveries
+	AddDeliveries(deliveries []*model.WebhookDelivery) error
+	// UpdateDelivery applies updateFn to the given delivery atomically and persists the result
+	UpdateDelivery(id string, updateFn func(*model.WebhookDelivery) error) error
+	// DeleteDelivery removes a delivery from the queue
+	DeleteDelivery(id string) error
+	// GetNextDeliveryID returns the next available delivery ID
+	GetNextDeliveryID() int
+}
@@ -0,0 +1,537 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order; PRAGMA user_version records how many have run.
+// Never edit an existing entry - append a new one instead.
+var migrations = []string{
+	// 1: auctions, bids and ID counters
+	`CREATE TABLE auctions (
+		id       TEXT PRIMARY KEY,
+		status   TEXT NOT NULL,
+		end_time INTEGER NOT NULL,
+		data     TEXT NOT NULL
+	);
+	CREATE INDEX idx_auctions_status ON auctions(status);
+	CREATE TABLE bids (
+		id         TEXT PRIMARY KEY,
+		auction_id TEXT NOT NULL REFERENCES auctions(id),
+		user_id    TEXT NOT NULL,
+		amount     REAL NOT NULL,
+		placed_at  INTEGER NOT NULL
+	);
+	CREATE INDEX idx_bids_auction_id ON bids(auction_id);
+	CREATE TABLE counters (
+		name  TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	);`,
+	// 2: bid flags and multi-unit quantities
+	`ALTER TABLE bids ADD COLUMN automatic INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE bids ADD COLUMN quantity INTEGER NOT NULL DEFAULT 1;`,
+	// 3: exact amounts in cents; amount is still written for older readers
+	`ALTER TABLE bids ADD COLUMN amount_cents INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE bids ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	UPDATE bids SET amount_cents = CAST(ROUND(amount * 100) AS INTEGER);`,
+	// 4: registered bidders
+	`CREATE TABLE users (
+		id   TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	);`,
+	// 5: webhooks and their delivery queue
+	`CREATE TABLE webhooks (
+		id   TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	);
+	CREATE TABLE webhook_deliveries (
+		id         TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+		status     TEXT NOT NULL,
+		data       TEXT NOT NULL
+	);
+	CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);`,
+}
+
+// SQLiteRepository is an AuctionRepository, UserRepository and
+// WebhookRepository backed by an embedded SQLite database. Everything is
+// loaded into memory on open and every write goes through to disk, so reads
+// never touch the database.
+type SQLiteRepository struct {
+	*MemoryRepository
+	db      *sql.DB
+	usersMu sync.Mutex // serializes registrations between the check and the write
+}
+
+// NewSQLiteRepository opens (or creates) the database at path, applies any pending
+// migrations and loads the stored auctions
+func NewSQLiteRepository(path string) (*SQLiteRepository, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer; serialising here avoids SQLITE_BUSY errors
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("PRAGMA foreign_keys = ON; PRAGMA journal_mode = WAL"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("configure sqlite database: %w", err)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	r := &SQLiteRepository{
+		MemoryRepository: NewMemoryRepository(),
+		db:               db,
+	}
+	if err := r.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Close closes the underlying database
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// SetAuction inserts or replaces an auction
+func (r *SQLiteRepository) SetAuction(auction *model.Auction) error {
+	if err := saveAuction(r.db, auction); err != nil {
+		return err
+	}
+	return r.MemoryRepository.SetAuction(auction)
+}
+
+// UpdateAuction updates the given auction atomically and writes it to disk.
+// The in-memory auction is only changed once the write has succeeded.
+func (r *SQLiteRepository) UpdateAuction(id string, updateFn func(*model.Auction) error) error {
+	return r.MemoryRepository.UpdateAuction(id, func(auction *model.Auction) error {
+		updated := *auction
+		if err := updateFn(&updated); err != nil {
+			return err
+		}
+		if err := saveAuction(r.db, &updated); err != nil {
+			return err
+		}
+		*auction = updated
+		return nil
+	})
+}
+
+// AddBid records a bid and the auction's new leading state in a single transaction
+func (r *SQLiteRepository) AddBid(bid *model.Bid) error {
+	return r.MemoryRepository.UpdateAuction(bid.AuctionID, func(auction *model.Auction) error {
+		// Apply to a copy first so memory only changes once the write has committed
+		updated := *auction
+		updated.ApplyBid(*bid)
+
+		tx, err := r.db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin bid transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(
+			`INSERT INTO bids (id, auction_id, user_id, amount, amount_cents, currency, placed_at, automatic, quantity) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			bid.ID, bid.AuctionID, bid.UserID, float64(bid.Amount.Cents)/100, bid.Amount.Cents, bid.Amount.Currency,
+			bid.Timestamp.UnixNano(), bid.Automatic, bid.Units(),
+		); err != nil {
+			return fmt.Errorf("insert bid: %w", err)
+		}
+		if err := saveAuction(tx, &updated); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit bid: %w", err)
+		}
+
+		*auction = updated
+		return nil
+	})
+}
+
+// AddUser registers a new user and writes it to disk
+func (r *SQLiteRepository) AddUser(user *model.User) error {
+	r.usersMu.Lock()
+	defer r.usersMu.Unlock()
+
+	if r.MemoryRepository.GetUser(user.ID) != nil {
+		return model.ErrAlreadyRegistered
+	}
+	if err := saveUser(r.db, user); err != nil {
+		return err
+	}
+	return r.MemoryRepository.AddUser(user)
+}
+
+// UpdateUser updates the given user atomically and writes it to disk. The
+// in-memory user is only changed once the write has succeeded.
+func (r *SQLiteRepository) UpdateUser(id string, updateFn func(*model.User) error) error {
+	return r.MemoryRepository.UpdateUser(id, func(user *model.User) error {
+		updated := *user
+		if err := updateFn(&updated); err != nil {
+			return err
+		}
+		if err := saveUser(r.db, &updated); err != nil {
+			return err
+		}
+		*user = updated
+		return nil
+	})
+}
+
+// AddWebhook registers a new webhook and writes it to disk
+func (r *SQLiteRepository) AddWebhook(webhook *model.Webhook) error {
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("encode webhook: %w", err)
+	}
+	if _, err := r.db.Exec(`INSERT INTO webhooks (id, data) VALUES (?, ?)`, webhook.ID, string(data)); err != nil {
+		return fmt.Errorf("save webhook %s: %w", webhook.ID, err)
+	}
+	return r.MemoryRepository.AddWebhook(webhook)
+}
+
+// DeleteWebhook removes a webhook and its queued deliveries from disk and memory
+func (r *SQLiteRepository) DeleteWebhook(id string) error {
+	if r.MemoryRepository.GetWebhook(id) == nil {
+		return model.ErrWebhookNotFound
+	}
+	if _, err := r.db.Exec(`DELETE FROM webhooks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete webhook %s: %w", id, err)
+	}
+	return r.MemoryRepository.DeleteWebhook(id)
+}
+
+// AddDeliveries queues new deliveries in a single transaction
+func (r *SQLiteRepository) AddDeliveries(deliveries []*model.WebhookDelivery) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		if err := saveDelivery(tx, delivery); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit deliveries: %w", err)
+	}
+	return r.MemoryRepository.AddDeliveries(deliveries)
+}
+
+// UpdateDelivery updates the given delivery atomically and writes it to disk.
+// The in-memory delivery is only changed once the write has succeeded.
+func (r *SQLiteRepository) UpdateDelivery(id string, updateFn func(*model.WebhookDelivery) error) error {
+	return r.MemoryRepository.UpdateDelivery(id, func(delivery *model.WebhookDelivery) error {
+		updated := *delivery
+		if err := updateFn(&updated); err != nil {
+			return err
+		}
+		if err := saveDelivery(r.db, &updated); err != nil {
+			return err
+		}
+		*delivery = updated
+		return nil
+	})
+}
+
+// DeleteDelivery removes a delivery from the queue on disk and in memory
+func (r *SQLiteRepository) DeleteDelivery(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM webhook_deliveries WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete delivery %s: %w", id, err)
+	}
+	return r.MemoryRepository.DeleteDelivery(id)
+}
+
+// GetNextWebhookID returns the next available webhook ID
+func (r *SQLiteRepository) GetNextWebhookID() int {
+	id := r.MemoryRepository.GetNextWebhookID()
+	r.saveCounter("webhook", id)
+	return id
+}
+
+// GetNextDeliveryID returns the next available delivery ID
+func (r *SQLiteRepository) GetNextDeliveryID() int {
+	id := r.MemoryRepository.GetNextDeliveryID()
+	r.saveCounter("delivery", id)
+	return id
+}
+
+// GetNextAuctionID returns the next available auction ID
+func (r *SQLiteRepository) GetNextAuctionID() int {
+	id := r.MemoryRepository.GetNextAuctionID()
+	r.saveCounter("auction", id)
+	return id
+}
+
+// GetNextBidID returns the next available bid ID
+func (r *SQLiteRepository) GetNextBidID() int {
+	id := r.MemoryRepository.GetNextBidID()
+	r.saveCounter("bid", id)
+	return id
+}
+
+// saveCounter records the last ID handed out so it is never reused after a restart
+func (r *SQLiteRepository) saveCounter(name string, value int) {
+	if _, err := r.db.Exec(
+		`INSERT INTO counters (name, value) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET value = MAX(value, excluded.value)`,
+		name, value,
+	); err != nil {
+		log.Printf("failed to persist %s counter: %v", name, err)
+	}
+}
+
+// load reads all auctions, bids, users, webhooks and counters into memory
+func (r *SQLiteRepository) load() error {
+	rows, err := r.db.Query(`SELECT data FROM auctions ORDER BY rowid`)
+	if err != nil {
+		return fmt.Errorf("load auctions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return fmt.Errorf("scan auction: %w", err)
+		}
+		auction := &model.Auction{}
+		if err := json.Unmarshal([]byte(data), auction); err != nil {
+			return fmt.Errorf("decode auction: %w", err)
+		}
+		auction.Bids = []model.Bid{}
+		if auction.Type == "" {
+			auction.Type = model.AuctionTypeEnglish // saved before auction types existed
+		}
+		if auction.Quantity == 0 {
+			auction.Quantity = 1 // saved before multi-unit lots existed
+		}
+		if auction.StartingBid.Currency == "" {
+			auction.SetCurrency(model.DefaultCurrency) // saved before amounts carried a currency
+		}
+		r.MemoryRepository.SetAuction(auction)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load auctions: %w", err)
+	}
+
+	bidRows, err := r.db.Query(`SELECT id, auction_id, user_id, amount_cents, currency, placed_at, automatic, quantity FROM bids ORDER BY rowid`)
+	if err != nil {
+		return fmt.Errorf("load bids: %w", err)
+	}
+	defer bidRows.Close()
+
+	for bidRows.Next() {
+		var bid model.Bid
+		var placedAt int64
+		if err := bidRows.Scan(&bid.ID, &bid.AuctionID, &bid.UserID, &bid.Amount.Cents, &bid.Amount.Currency, &placedAt, &bid.Automatic, &bid.Quantity); err != nil {
+			return fmt.Errorf("scan bid: %w", err)
+		}
+		bid.Timestamp = time.Unix(0, placedAt)
+		if auction := r.auctions[bid.AuctionID]; auction != nil {
+			if bid.Amount.Currency == "" {
+				bid.Amount.Currency = auction.Currency() // saved before amounts carried a currency
+			}
+			auction.Bids = append(auction.Bids, bid)
+		}
+	}
+	if err := bidRows.Err(); err != nil {
+		return fmt.Errorf("load bids: %w", err)
+	}
+
+	userRows, err := r.db.Query(`SELECT data FROM users ORDER BY rowid`)
+	if err != nil {
+		return fmt.Errorf("load users: %w", err)
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var data string
+		if err := userRows.Scan(&data); err != nil {
+			return fmt.Errorf("scan user: %w", err)
+		}
+		user := &model.User{}
+		if err := json.Unmarshal([]byte(data), user); err != nil {
+			return fmt.Errorf("decode user: %w", err)
+		}
+		r.MemoryRepository.AddUser(user)
+	}
+	if err := userRows.Err(); err != nil {
+		return fmt.Errorf("load users: %w", err)
+	}
+
+	webhookRows, err := r.db.Query(`SELECT data FROM webhooks ORDER BY rowid`)
+	if err != nil {
+		return fmt.Errorf("load webhooks: %w", err)
+	}
+	defer webhookRows.Close()
+
+	for webhookRows.Next() {
+		var data string
+		if err := webhookRows.Scan(&data); err != nil {
+			return fmt.Errorf("scan webhook: %w", err)
+		}
+		webhook := &model.Webhook{}
+		if err := json.Unmarshal([]byte(data), webhook); err != nil {
+			return fmt.Errorf("decode webhook: %w", err)
+		}
+		r.MemoryRepository.AddWebhook(webhook)
+	}
+	if err := webhookRows.Err(); err != nil {
+		return fmt.Errorf("load webhooks: %w", err)
+	}
+
+	deliveryRows, err := r.db.Query(`SELECT data FROM webhook_deliveries ORDER BY rowid`)
+	if err != nil {
+		return fmt.Errorf("load webhook deliveries: %w", err)
+	}
+	defer deliveryRows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for deliveryRows.Next() {
+		var data string
+		if err := deliveryRows.Scan(&data); err != nil {
+			return fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		delivery := &model.WebhookDelivery{}
+		if err := json.Unmarshal([]byte(data), delivery); err != nil {
+			return fmt.Errorf("decode webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := deliveryRows.Err(); err != nil {
+		return fmt.Errorf("load webhook deliveries: %w", err)
+	}
+	r.MemoryRepository.AddDeliveries(deliveries)
+
+	counterRows, err := r.db.Query(`SELECT name, value FROM counters`)
+	if err != nil {
+		return fmt.Errorf("load counters: %w", err)
+	}
+	defer counterRows.Close()
+
+	for counterRows.Next() {
+		var name string
+		var value int
+		if err := counterRows.Scan(&name, &value); err != nil {
+			return fmt.Errorf("scan counter: %w", err)
+		}
+		switch name {
+		case "auction":
+			r.nextAuctionID = value + 1
+		case "bid":
+			r.nextBidID = value + 1
+		case "webhook":
+			r.nextWebhookID = value + 1
+		case "delivery":
+			r.nextDeliveryID = value + 1
+		}
+	}
+	return counterRows.Err()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// saveAuction upserts an auction row. Bids are stored separately, so they are
+// left out of the serialized data.
+func saveAuction(db execer, auction *model.Auction) error {
+	row := *auction
+	row.Bids = nil
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("encode auction: %w", err)
+	}
+
+	if _, err := db.Exec(
+		`INSERT INTO auctions (id, status, end_time, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET status = excluded.status, end_time = excluded.end_time, data = excluded.data`,
+		auction.ID, string(auction.Status), auction.EndTime.UnixNano(), string(data),
+	); err != nil {
+		return fmt.Errorf("save auction %s: %w", auction.ID, err)
+	}
+	return nil
+}
+
+// saveUser upserts a user row
+func saveUser(db execer, user *model.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("encode user: %w", err)
+	}
+
+	if _, err := db.Exec(
+		`INSERT INTO users (id, data) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data`,
+		user.ID, string(data),
+	); err != nil {
+		return fmt.Errorf("save user %s: %w", user.ID, err)
+	}
+	return nil
+}
+
+// saveDelivery upserts a webhook delivery row
+func saveDelivery(db execer, delivery *model.WebhookDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("encode delivery: %w", err)
+	}
+
+	if _, err := db.Exec(
+		`INSERT INTO webhook_deliveries (id, webhook_id, status, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET status = excluded.status, data = excluded.data`,
+		delivery.ID, delivery.WebhookID, string(delivery.Status), string(data),
+	); err != nil {
+		return fmt.Errorf("save delivery %s: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// migrate brings the database schema up to date
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA doesn't accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+)
+
+// usd returns a whole number of US dollars
+func usd(units int64) model.Money {
+	return model.WholeUnits(units, "USD")
+}
+
+func newTestAuction(id string) *model.Auction {
+	now := time.Now()
+	return &model.Auction{
+		ID:          id,
+		StartingBid: usd(100),
+		CurrentBid:  usd(100),
+		Duration:    30,
+		StartTime:   now,
+		EndTime:     now.Add(30 * time.Second),
+		Status:      model.AuctionStatusActive,
+		Bids:        []model.Bid{},
+	}
+}
+
+func TestSQLiteRepository_RestoresAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auction.db")
+
+	repo, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	auctionID := repo.GetNextAuctionID()
+	auction := newTestAuction("auction-1")
+	if err := repo.SetAuction(auction); err != nil {
+		t.Fatalf("set auction failed: %v", err)
+	}
+
+	bid := &model.Bid{
+		ID:        "bid-1",
+		AuctionID: auction.ID,
+		UserID:    "user1",
+		Amount:    usd(150),
+		Quantity:  1,
+		Automatic: true,
+		Timestamp: time.Now(),
+	}
+	repo.GetNextBidID()
+	if err := repo.AddBid(bid); err != nil {
+		t.Fatalf("add bid failed: %v", err)
+	}
+
+	if err := repo.UpdateAuction(auction.ID, func(a *model.Auction) error {
+		a.Status = model.AuctionStatusEnded
+		return nil
+	}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	repo.Close()
+
+	reopened, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	restored := reopened.GetAuction(auction.ID)
+	if restored == nil {
+		t.Fatal("expected auction to be restored")
+	}
+	if restored.Status != model.AuctionStatusEnded {
+		t.Errorf("expected status ENDED, got %s", restored.Status)
+	}
+	if restored.CurrentBid != usd(150) {
+		t.Errorf("expected current bid 150.0, got %s", restored.CurrentBid)
+	}
+	if restored.CurrentWinner == nil || *restored.CurrentWinner != "user1" {
+		t.Errorf("expected winner user1, got %v", restored.CurrentWinner)
+	}
+	if len(restored.Bids) != 1 || restored.Bids[0].ID != "bid-1" {
+		t.Errorf("expected bid-1 to be restored, got %+v", restored.Bids)
+	} else if !restored.Bids[0].Automatic || restored.Bids[0].Quantity != 1 {
+		t.Errorf("expected automatic bid for 1 unit, got %+v", restored.Bids[0])
+	}
+
+	if next := reopened.GetNextAuctionID(); next != auctionID+1 {
+		t.Errorf("expected next auction ID %d, got %d", auctionID+1, next)
+	}
+	if next := reopened.GetNextBidID(); next != 2 {
+		t.Errorf("expected next bid ID 2, got %d", next)
+	}
+}
+
+func TestSQLiteRepository_AddBidUnknownAuction(t *testing.T) {
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "auction.db"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer repo.Close()
+
+	err = repo.AddBid(&model.Bid{ID: "bid-1", AuctionID: "auction-404", UserID: "user1", Amount: usd(150)})
+	if err != model.ErrAuctionNotFound {
+		t.Errorf("expected ErrAuctionNotFound, got %v", err)
+	}
+}
+
+func TestSQLiteRepository_RestoresUsers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auction.db")
+
+	repo, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	user := &model.User{ID: "alice", DisplayName: "Alice", Verification: model.VerificationPending, RegisteredAt: time.Now()}
+	if err := repo.AddUser(user); err != nil {
+		t.Fatalf("add user failed: %v", err)
+	}
+	if err := repo.AddUser(&model.User{ID: "alice", DisplayName: "Impostor"}); err != model.ErrAlreadyRegistered {
+		t.Errorf("expected ErrAlreadyRegistered, got %v", err)
+	}
+	if err := repo.UpdateUser("alice", func(u *model.User) error {
+		u.Suspended = true
+		u.SuspensionReason = "chargeback"
+		return nil
+	}); err != nil {
+		t.Fatalf("update user failed: %v", err)
+	}
+	repo.Close()
+
+	reopened, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	restored := reopened.GetUser("alice")
+	if restored == nil {
+		t.Fatal("expected alice to be restored")
+	}
+	if restored.DisplayName != "Alice" || !restored.Suspended || restored.SuspensionReason != "chargeback" {
+		t.Errorf("unexpected restored user: %+v", restored)
+	}
+}
+
+func TestSQLiteRepository_RestoresWebhookQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auction.db")
+
+	repo, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for _, id := range []string{"webhook-1", "webhook-2"} {
+		repo.GetNextWebhookID()
+		webhook := &model.Webhook{ID: id, URL: "https://example.com/hook", EventTypes: []model.AuctionEventType{model.EventAuctionEnded}, Secret: "0123456789abcdef"}
+		if err := repo.AddWebhook(webhook); err != nil {
+			t.Fatalf("add webhook failed: %v", err)
+		}
+	}
+	var deliveries []*model.WebhookDelivery
+	for i, webhookID := range []string{"webhook-1", "webhook-1", "webhook-2"} {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			ID:        "delivery-" + string(rune('1'+i)),
+			WebhookID: webhookID,
+			EventType: model.EventAuctionEnded,
+			Payload:   []byte(`{"type":"AUCTION_ENDED"}`),
+			Status:    model.DeliveryPending,
+		})
+		repo.GetNextDeliveryID()
+	}
+	if err := repo.AddDeliveries(deliveries); err != nil {
+		t.Fatalf("add deliveries failed: %v", err)
+	}
+	if err := repo.UpdateDelivery("delivery-2", func(d *model.WebhookDelivery) error {
+		d.Status = model.DeliveryDead
+		d.Attempts = 8
+		return nil
+	}); err != nil {
+		t.Fatalf("update delivery failed: %v", err)
+	}
+	if err := repo.DeleteWebhook("webhook-2"); err != nil {
+		t.Fatalf("delete webhook failed: %v", err)
+	}
+	repo.Close()
+
+	reopened, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if webhooks := reopened.ListWebhooks(); len(webhooks) != 1 || webhooks[0].Secret != "0123456789abcdef" {
+		t.Errorf("expected webhook-1 with its secret, got %+v", webhooks)
+	}
+	pending := reopened.ListDeliveries(model.DeliveryPending)
+	if len(pending) != 1 || pending[0].ID != "delivery-1" || string(pending[0].Payload) != `{"type":"AUCTION_ENDED"}` {
+		t.Errorf("expected delivery-1 with its payload to be pending, got %+v", pending)
+	}
+	if dead := reopened.ListDeliveries(model.DeliveryDead); len(dead) != 1 || dead[0].Attempts != 8 {
+		t.Errorf("expected delivery-2 in the dead-letter list, got %+v", dead)
+	}
+	if got := reopened.GetNextDeliveryID(); got != 4 {
+		t.Errorf("expected the delivery counter to survive, got %d", got)
+	}
+}
@@ -2,13 +2,23 @@
 package main
 
 import (
+	"context"
 	"log"
+	"maps"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/micahli/fl-auction/auction-server/graph"
+	"github.com/micahli/fl-auction/auction-server/internal/auth"
+	"github.com/micahli/fl-auction/auction-server/internal/clock"
+	"github.com/micahli/fl-auction/auction-server/internal/eventlog"
+	"github.com/micahli/fl-auction/auction-server/internal/model"
+	"github.com/micahli/fl-auction/auction-server/internal/ratelimit"
 	"github.com/micahli/fl-auction/auction-server/internal/service"
+	"github.com/micahli/fl-auction/auction-server/internal/sse"
 	"github.com/micahli/fl-auction/auction-server/internal/store"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -19,7 +29,11 @@ import (
 	"github.com/rs/cors"
 )
 
-const defaultPort = "8080"
+const (
+	defaultPort         = "8080"
+	defaultStoreBackend = "memory"
+	defaultDatabasePath = "auction.db"
+)
 
 func main() {
 	port := os.Getenv("PORT")
@@ -27,28 +41,98 @@ func main() {
 		port = defaultPort
 	}
 
+	// Open the event log, if configured
+	var serviceOpts []service.Option
+	var records []eventlog.Record
+	if path := os.Getenv("EVENT_LOG_PATH"); path != "" {
+		eventLog, err := eventlog.Open(path)
+		if err != nil {
+			log.Fatalf("failed to open event log: %v", err)
+		}
+		defer eventLog.Close()
+
+		if records, err = eventLog.Records(); err != nil {
+			log.Fatalf("failed to read event log: %v", err)
+		}
+		serviceOpts = append(serviceOpts, service.WithEventLog(eventLog))
+		log.Printf("📜 Recording auction events to %s", path)
+	}
+
+	if v := os.Getenv("BUY_NOW_CUTOFF_PERCENT"); v != "" {
+		percent, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || percent <= 0 {
+			log.Fatalf("invalid BUY_NOW_CUTOFF_PERCENT %q", v)
+		}
+		serviceOpts = append(serviceOpts, service.WithBuyNowCutoff(percent))
+	}
+
+	if v := os.Getenv("IDEMPOTENCY_WINDOW"); v != "" {
+		window, err := time.ParseDuration(v)
+		if err != nil || window <= 0 {
+			log.Fatalf("invalid IDEMPOTENCY_WINDOW %q", v)
+		}
+		serviceOpts = append(serviceOpts, service.WithIdempotencyWindow(window))
+	}
+
 	// Initialize the data store
-	auctionStore := store.NewAuctionStore()
+	auctionStore, userRepo, webhookRepo, closeStore := newStores(records)
+	defer closeStore()
 
 	// Initialize the service layer
-	auctionService := service.NewAuctionService(auctionStore)
+	var userOpts []service.UserOption
+	if os.Getenv("AUTO_VERIFY_BIDDERS") == "true" {
+		userOpts = append(userOpts, service.WithAutoVerify())
+		log.Printf("🪪 Verifying bidders as soon as they register")
+	}
+	if v := os.Getenv("STARTING_CREDIT"); v != "" {
+		credit, err := model.ParseMoney(v)
+		if err != nil || !credit.IsPositive() {
+			log.Fatalf("invalid STARTING_CREDIT %q", v)
+		}
+		userOpts = append(userOpts, service.WithStartingCredit(credit))
+		log.Printf("💳 Depositing %s for every new bidder", credit)
+	}
+	userService := service.NewUserService(userRepo, userOpts...)
+	serviceOpts = append(serviceOpts, service.WithBidderRegistry(userService), service.WithCreditLedger(userService))
+	auctionService := service.NewAuctionService(auctionStore, serviceOpts...)
+
+	// Queue every broadcast event for the webhooks registered for it
+	webhookService := service.NewWebhookService(webhookRepo, webhookOptions()...)
+	auctionStore.AddListener(webhookService.Enqueue)
+	go webhookService.Run(context.Background())
+
+	// Pick up auctions that were still running when the server last stopped
+	if resumed := auctionService.ResumeCountdowns(); resumed > 0 {
+		log.Printf("⏱️  Resumed %d active auction(s)", resumed)
+	}
+
+	// Bidding needs a verified user
+	verifier := newVerifier()
 
 	// Create the GraphQL resolver
-	resolver := graph.NewResolver(auctionService, auctionStore)
+	resolver := graph.NewResolver(auctionService, userService, webhookService, auctionStore)
 
 	// Create the GraphQL server with the generated schema
 	srv := handler.New(graph.NewExecutableSchema(graph.Config{
-		Resolvers: resolver,
+		Resolvers:  resolver,
+		Directives: graph.Directives(),
 	}))
 
+	// Report model errors with machine-readable codes
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+
 	// Configure HTTP transports
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
+	// SSE goes ahead of POST, which would otherwise take its requests
+	srv.AddTransport(transport.SSE{})
 	srv.AddTransport(transport.POST{})
 
 	// Configure WebSocket transport for subscriptions
 	srv.AddTransport(&transport.Websocket{
 		KeepAlivePingInterval: 10 * time.Second,
+		// Subscription clients send their token in the connection_init payload
+		InitFunc: verifier.WebsocketInit,
 		Upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				// In production, validate origin properly
@@ -61,6 +145,7 @@ func main() {
 
 	// Add GraphQL extensions
 	srv.Use(extension.Introspection{})
+	srv.Use(graph.NewRateLimit(rateLimits(), clock.Real))
 
 	// Configure CORS for frontend access
 	corsHandler := cors.New(cors.Options{
@@ -73,19 +158,166 @@ func main() {
 
 	// Setup HTTP routes
 	http.Handle("/", playground.Handler("GraphQL Playground", "/query"))
-	http.Handle("/query", corsHandler.Handler(srv))
+	http.Handle("/query", corsHandler.Handler(ratelimit.ClientIP(trustedProxies(), verifier.Middleware(sse.LastEventID(srv)))))
+	http.Handle("GET /events/{auctionId}", corsHandler.Handler(verifier.Middleware(sse.NewHandler(auctionService))))
 
 	// Start the server
 	log.Printf("🚀 Server starting on http://localhost:%s", port)
 	log.Printf("📊 GraphQL Playground: http://localhost:%s/", port)
 	log.Printf("🔌 GraphQL Endpoint: http://localhost:%s/query", port)
 	log.Printf("⚡ WebSocket Endpoint: ws://localhost:%s/query", port)
+	log.Printf("📡 SSE Endpoint: http://localhost:%s/events/{auctionId}", port)
 	log.Printf("\n📝 Try these queries in the playground:\n")
 	log.Printf("   - Create auction: mutation { createAuction(startingBid: 100, duration: 30, extendedBidding: true) { id status } }\n")
-	log.Printf("   - Place bid: mutation { placeBid(userId: \"user123\", amount: 150) { id amount } }\n")
-	log.Printf("   - Subscribe: subscription { auctionEvents { type auction { currentBid currentWinner timeRemaining } } }\n")
+	log.Printf("   - List auctions: query { auctions(status: ACTIVE) { id currentBid timeRemaining } }\n")
+	log.Printf("   - Place bid (with a BIDDER's Authorization: Bearer header): mutation { placeBid(auctionId: \"auction-1\", amount: 150) { id amount } }\n")
+	log.Printf("   - Subscribe: subscription { auctionEvents(auctionId: \"auction-1\") { type auction { currentBid currentWinner timeRemaining } } }\n")
 
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// rateLimits returns the default mutation rate limits, overridden per mutation
+// by RATE_LIMITS, e.g. "placeBid=5/s:10,*=20/s"
+func rateLimits() map[string]ratelimit.Rule {
+	rules := graph.DefaultRateLimits()
+	if v := os.Getenv("RATE_LIMITS"); v != "" {
+		overrides, err := ratelimit.ParseRules(v)
+		if err != nil {
+			log.Fatalf("invalid RATE_LIMITS: %v", err)
+		}
+		maps.Copy(rules, overrides)
+	}
+	return rules
+}
+
+// trustedProxies returns how many reverse proxies in front of the server append
+// to X-Forwarded-For, from RATE_LIMIT_TRUST_FORWARDED: "true" for one, or a
+// count
+func trustedProxies() int {
+	v := os.Getenv("RATE_LIMIT_TRUST_FORWARDED")
+	switch v {
+	case "", "false":
+		return 0
+	case "true":
+		return 1
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid RATE_LIMIT_TRUST_FORWARDED %q: want true, false or a number of proxies", v)
+	}
+	return n
+}
+
+// webhookOptions configures webhook retries from WEBHOOK_MAX_ATTEMPTS and
+// WEBHOOK_BACKOFF, the wait before the first retry, and how many webhooks are
+// posted to at once from WEBHOOK_WORKERS
+func webhookOptions() []service.WebhookOption {
+	attempts, backoff := service.DefaultWebhookAttempts, service.DefaultWebhookBackoff
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid WEBHOOK_MAX_ATTEMPTS %q", v)
+		}
+		attempts = n
+	}
+	if v := os.Getenv("WEBHOOK_BACKOFF"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid WEBHOOK_BACKOFF %q", v)
+		}
+		backoff = d
+	}
+	opts := []service.WebhookOption{service.WithWebhookRetries(attempts, backoff)}
+	if v := os.Getenv("WEBHOOK_WORKERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid WEBHOOK_WORKERS %q", v)
+		}
+		opts = append(opts, service.WithWebhookWorkers(n))
+	}
+	return opts
+}
+
+// newVerifier configures bearer token verification from JWT_HMAC_SECRET and
+// JWT_PUBLIC_KEY_FILE (a comma-separated list of PEM files with RSA or ECDSA
+// keys), optionally pinning JWT_ISSUER and JWT_AUDIENCE. Tokens without an
+// expiry are refused unless JWT_ALLOW_NO_EXPIRY is true.
+func newVerifier() *auth.Verifier {
+	var opts []auth.Option
+	if secret := os.Getenv("JWT_HMAC_SECRET"); secret != "" {
+		opts = append(opts, auth.WithHMACSecret([]byte(secret)))
+	}
+	if files := os.Getenv("JWT_PUBLIC_KEY_FILE"); files != "" {
+		for _, path := range strings.Split(files, ",") {
+			data, err := os.ReadFile(strings.TrimSpace(path))
+			if err != nil {
+				log.Fatalf("failed to read JWT public key: %v", err)
+			}
+			key, err := auth.ParsePublicKeyPEM(data)
+			if err != nil {
+				log.Fatalf("invalid JWT public key in %s: %v", path, err)
+			}
+			opts = append(opts, auth.WithPublicKey(key))
+		}
+	}
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		opts = append(opts, auth.WithIssuer(issuer))
+	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		opts = append(opts, auth.WithAudience(audience))
+	}
+	if os.Getenv("JWT_ALLOW_NO_EXPIRY") == "true" {
+		opts = append(opts, auth.AllowNoExpiry())
+		log.Printf("⚠️  Accepting JWT bearer tokens without an expiry")
+	}
+
+	verifier := auth.NewVerifier(opts...)
+	if verifier.HasKeys() {
+		log.Printf("🔐 Verifying JWT bearer tokens")
+	} else {
+		log.Printf("⚠️  No JWT keys configured (JWT_HMAC_SECRET or JWT_PUBLIC_KEY_FILE): bidding is disabled")
+	}
+	return verifier
+}
+
+// newStores builds the auction store, bidder registry and webhook queue for the
+// backend selected by STORE_BACKEND ("memory" or "sqlite"). The memory backend
+// rebuilds its auctions by replaying the event log records; its bidders and
+// webhooks don't survive a restart. It returns a func that releases the backend.
+func newStores(records []eventlog.Record) (*store.AuctionStore, store.UserRepository, store.WebhookRepository, func()) {
+	backend := os.Getenv("STORE_BACKEND")
+	if backend == "" {
+		backend = defaultStoreBackend
+	}
+
+	switch backend {
+	case "memory":
+		repo := store.NewMemoryRepository()
+		if len(records) > 0 {
+			auctions, err := eventlog.Replay(records)
+			if err != nil {
+				log.Fatalf("failed to replay event log: %v", err)
+			}
+			repo.Restore(auctions)
+			log.Printf("📜 Replayed %d event(s) into %d auction(s)", len(records), len(auctions))
+		}
+		log.Printf("💾 Using in-memory store")
+		return store.NewAuctionStoreWithRepository(repo), repo, repo, func() {}
+	case "sqlite":
+		path := os.Getenv("DATABASE_PATH")
+		if path == "" {
+			path = defaultDatabasePath
+		}
+		repo, err := store.NewSQLiteRepository(path)
+		if err != nil {
+			log.Fatalf("failed to open sqlite store: %v", err)
+		}
+		log.Printf("💾 Using SQLite store at %s", path)
+		return store.NewAuctionStoreWithRepository(repo), repo, repo, func() { repo.Close() }
+	default:
+		log.Fatalf("unknown STORE_BACKEND %q (expected \"memory\" or \"sqlite\")", backend)
+		return nil, nil, nil, nil
+	}
+}